@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -12,17 +13,31 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/clients"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/handlers"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/health"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/idempotency"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/logging"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/metrics"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/outbox"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/repository"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/service"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/service/pricing"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/telemetry"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/verification"
 )
 
 func main() {
+	reconcileOnce := flag.Bool("reconcile-once", false, "run a single reconciliation pass over recently created orders and exit, instead of starting the server")
+	flag.Parse()
+
 	logger := logging.NewLogger()
 
 	cfg, err := config.Load()
@@ -39,8 +54,24 @@ func main() {
 		slog.Duration("db_timeout", cfg.DBTimeout),
 		slog.Int("circuit_threshold", cfg.CircuitThreshold),
 		slog.Bool("idempotency_enabled", cfg.IdempotencyEnabled),
+		slog.Duration("request_deadline", cfg.RequestDeadline),
 	)
 
+	// Tracing: off by default so a deployment without a collector doesn't
+	// dial one at startup.
+	var telemetryShutdown func(context.Context) error
+	if cfg.OTelEnabled {
+		telemetryShutdown, err = telemetry.Setup(context.Background(), telemetry.Config{
+			Endpoint:    cfg.OTelExporterEndpoint,
+			SampleRatio: cfg.OTelSampleRatio,
+			ServiceName: cfg.OTelServiceName,
+		})
+		if err != nil {
+			logger.Error("Failed to set up telemetry", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	// Database connection
 	dbCfg, err := pgxpool.ParseConfig(cfg.DatabaseURL)
 	if err != nil {
@@ -55,21 +86,242 @@ func main() {
 	}
 	defer pool.Close()
 
-	// Books client
-	booksClient := clients.NewHTTPBooksClient(cfg.BooksServiceURL, cfg.HTTPTimeout, logger)
+	// Books client: HTTP by default, gRPC when BOOKS_TRANSPORT=grpc.
+	var booksClient clients.BooksClient
+	var debugBooksClient *clients.HTTPBooksClient
+	switch cfg.BooksTransport {
+	case "grpc":
+		conn, err := grpc.NewClient(cfg.BooksServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			logger.Error("Failed to dial Books gRPC endpoint", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		booksClient = clients.NewGRPCBooksClient(conn, metrics.NewBooksMetrics())
+	default:
+		httpClient := clients.NewHTTPBooksClientWithBatchMax(cfg.BooksServiceURL, cfg.HTTPTimeout, logger, metrics.NewBooksMetrics(), cfg.BooksBatchMax)
+		booksClient = httpClient
+		debugBooksClient = httpClient
+	}
+	booksClient = clients.NewRetryingBooksClientWithConfig(booksClient, clients.RetryConfig{
+		MaxAttempts: cfg.BooksRetryMaxAttempts,
+		MaxElapsed:  cfg.BooksRetryMaxElapsed,
+		BaseDelay:   cfg.BooksRetryBaseDelay,
+		MaxDelay:    cfg.BooksRetryMaxDelay,
+	})
+
+	// Order lifecycle events: Redis-backed when REDIS_URL is set, otherwise
+	// the service publishes to events.NoopPublisher and the WS endpoint is
+	// not registered.
+	var eventStream events.Stream
+	var redisPublisher *events.RedisPublisher
+	if cfg.RedisURL != "" {
+		redisOpts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			logger.Error("Failed to parse REDIS_URL", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		redisClient := redis.NewClient(redisOpts)
+		defer redisClient.Close()
+		redisPublisher = events.NewRedisPublisher(redisClient)
+		eventStream = redisPublisher
+	}
+
+	// Signed-order payload verification: nil until a signing secret (or
+	// EIP-191 mode) is configured, which is what leaves CreateSignedOrder
+	// rejecting with ServiceUnavailableError.
+	var verifier verification.PayloadVerifier
+	switch {
+	case cfg.SignatureVerification == "eip191":
+		verifier = verification.NewEIP191Verifier()
+	case cfg.OrderSigningSecret != "":
+		verifier = verification.NewStaticHMACVerifier(cfg.OrderSigningSecret)
+	}
 
 	// Repository and service
-	ordersRepo := repository.NewOrdersRepository(pool)
-	ordersService := service.NewOrdersService(ordersRepo, booksClient, logger, cfg)
+	ordersRepo := repository.NewOrdersRepositoryWithConfig(pool, cfg.BatchItemInsertEnabled)
+	var publisher events.EventPublisher = events.NoopPublisher{}
+	if redisPublisher != nil {
+		publisher = redisPublisher
+	}
+	serviceOpts := []service.Option{
+		service.WithRepository(ordersRepo),
+		service.WithBooksClient(booksClient),
+		service.WithLogger(logger),
+		service.WithConfig(cfg),
+		service.WithEventPublisher(publisher),
+		service.WithVerifier(verifier),
+	}
+	if cfg.FXProviderURL != "" {
+		serviceOpts = append(serviceOpts, service.WithFXProvider(service.NewHTTPFXProvider(cfg.FXProviderURL, 5*time.Second)))
+	}
+	if cfg.PricingPipelineEnabled {
+		var modifiers []pricing.PriceModifier
+		if cfg.TaxDefaultRate != "" {
+			rate, err := decimal.NewFromString(cfg.TaxDefaultRate)
+			if err != nil {
+				logger.Error("Invalid TAX_DEFAULT_RATE", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			modifiers = append(modifiers, pricing.TaxModifier{Label: "Tax", DefaultRate: rate})
+		}
+		if cfg.ShippingFlatFee != "" {
+			fee, err := decimal.NewFromString(cfg.ShippingFlatFee)
+			if err != nil {
+				logger.Error("Invalid SHIPPING_FLAT_FEE", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			modifiers = append(modifiers, pricing.ShippingModifier{Label: "Shipping", Flat: fee})
+		}
+		serviceOpts = append(serviceOpts, service.WithPricingPipeline(&pricing.Pipeline{Modifiers: modifiers}))
+
+		if len(cfg.DiscountCodes) > 0 {
+			codes := make(map[string]decimal.Decimal, len(cfg.DiscountCodes))
+			for code, percent := range cfg.DiscountCodes {
+				p, err := decimal.NewFromString(percent)
+				if err != nil {
+					logger.Error("Invalid DISCOUNT_CODES percent", slog.String("code", code), slog.String("error", err.Error()))
+					os.Exit(1)
+				}
+				codes[code] = p
+			}
+			serviceOpts = append(serviceOpts, service.WithDiscountCodes(codes))
+		}
+	}
+	ordersService := service.NewOrdersService(serviceOpts...)
+
+	// Idempotency key sweeper: uses its own pool so the cleanup job can't
+	// starve request-serving connections, and only runs when the feature is on.
+	var sweeperCancel context.CancelFunc
+	if cfg.IdempotencyEnabled {
+		bgCfg, err := pgxpool.ParseConfig(cfg.BackgroundDatabaseURL)
+		if err != nil {
+			logger.Error("Failed to parse background database configuration", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		bgCfg.MaxConns = int32(cfg.BackgroundMaxConns)
+
+		bgPool, err := pgxpool.NewWithConfig(context.Background(), bgCfg)
+		if err != nil {
+			logger.Error("Failed to create background database pool", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer bgPool.Close()
+
+		bgRepo := repository.NewOrdersRepository(bgPool)
+		sweeper := idempotency.NewSweeper(bgRepo, cfg.IdempotencyTTL, cfg.IdempotencySweepInterval, logger)
+
+		var sweeperCtx context.Context
+		sweeperCtx, sweeperCancel = context.WithCancel(context.Background())
+		go sweeper.Run(sweeperCtx)
+	}
+
+	// Outbox dispatcher: delivers orders_outbox rows (written in the same
+	// transaction as the order change) at-least-once, so a downstream outage
+	// at request time doesn't silently drop the event the way the in-request
+	// publisher above does. Runs unconditionally, on its own pool, so it
+	// can't starve request-serving connections.
+	outboxBgCfg, err := pgxpool.ParseConfig(cfg.BackgroundDatabaseURL)
+	if err != nil {
+		logger.Error("Failed to parse background database configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	outboxBgCfg.MaxConns = int32(cfg.BackgroundMaxConns)
+
+	outboxPool, err := pgxpool.NewWithConfig(context.Background(), outboxBgCfg)
+	if err != nil {
+		logger.Error("Failed to create outbox database pool", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer outboxPool.Close()
+
+	var outboxPublisher outbox.Publisher = outbox.NoopPublisher{}
+	if cfg.RedisURL != "" {
+		redisOpts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			logger.Error("Failed to parse REDIS_URL", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		outboxRedisClient := redis.NewClient(redisOpts)
+		defer outboxRedisClient.Close()
+		outboxPublisher = outbox.NewRedisPublisher(outboxRedisClient)
+	}
+
+	outboxStore := outbox.NewPostgresStore(outboxPool)
+	dispatcher := outbox.NewDispatcher(outboxStore, outboxPublisher, logger, cfg.OutboxPollInterval, cfg.OutboxMaxBackoff, cfg.OutboxBatchSize)
+	dispatcherCtx, dispatcherCancel := context.WithCancel(context.Background())
+	go dispatcher.Run(dispatcherCtx)
+
+	// Idempotency response cache: lets a retried CreateOrder request within the
+	// TTL window replay the original response instead of re-running it. Backed
+	// by Postgres by default; Redis when IDEMPOTENCY_STORE_BACKEND=redis and
+	// REDIS_URL is set, so the cache can scale horizontally off the primary DB.
+	var idemStore idempotency.Store
+	if cfg.IdempotencyStoreBackend == "redis" && cfg.RedisURL != "" {
+		redisOpts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			logger.Error("Failed to parse REDIS_URL", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		idemStore = idempotency.NewRedisStore(redis.NewClient(redisOpts), cfg.IdempotencyTTL)
+	} else {
+		idemStore = idempotency.NewPostgresStore(pool, cfg.IdempotencyTTL)
+	}
+
+	// Reconciler: re-checks recently created orders against the Books
+	// service for drift (book deleted, price/title changed since the order
+	// snapshotted it). Own pool, same reasoning as the sweeper and dispatcher
+	// above.
+	reconcileBgCfg, err := pgxpool.ParseConfig(cfg.BackgroundDatabaseURL)
+	if err != nil {
+		logger.Error("Failed to parse background database configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	reconcileBgCfg.MaxConns = int32(cfg.BackgroundMaxConns)
+
+	reconcilePool, err := pgxpool.NewWithConfig(context.Background(), reconcileBgCfg)
+	if err != nil {
+		logger.Error("Failed to create reconciler database pool", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer reconcilePool.Close()
+
+	reconciler := service.NewReconciler(repository.NewOrdersRepository(reconcilePool), booksClient, logger, cfg.ReconcileInterval, cfg.ReconcileLookback)
+
+	if *reconcileOnce {
+		since := time.Now().Add(-cfg.ReconcileLookback)
+		anomalies, err := reconciler.ReconcileSince(context.Background(), since)
+		if err != nil {
+			logger.Error("One-shot reconciliation failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		logger.Info("One-shot reconciliation complete", slog.Int("anomalies_found", anomalies))
+		return
+	}
+
+	reconcilerCtx, reconcilerCancel := context.WithCancel(context.Background())
+	go reconciler.Run(reconcilerCtx)
+
+	// Background dependency health checks: DB is required for /readyz since
+	// nothing works without it; Books only degrades readiness, since the
+	// circuit breaker + retries already handle it being down on the request
+	// path.
+	healthRegistry := health.NewRegistry(logger)
+	healthRegistry.Register(health.NewDBChecker(pool), true)
+	healthRegistry.Register(health.NewBooksChecker(booksClient), false)
+	healthCtx, healthCancel := context.WithCancel(context.Background())
+	go healthRegistry.Run(healthCtx, cfg.HealthCheckInterval)
 
 	// Handlers
-	ordersHandler := handlers.NewOrdersHandler(ordersService, logger)
-	healthHandler := handlers.NewHealthHandler(pool, booksClient, logger)
+	ordersHandler := handlers.NewOrdersHandlerWithIdempotencyStore(ordersService, logger, cfg.RequestDeadline, idemStore)
+	healthHandler := handlers.NewHealthHandler(healthRegistry)
+	debugHandler := handlers.NewDebugHandler(debugBooksClient)
+	reconcileHandler := handlers.NewReconcileHandler(reconciler)
 
 	// Router setup
 	r := gin.New()
 	r.Use(gin.Recovery())
 	r.Use(metrics.Middleware())
+	r.Use(telemetry.Middleware())
 	r.Use(func(c *gin.Context) {
 		// Simple request ID middleware
 		requestID := c.GetHeader("X-Request-ID")
@@ -88,10 +340,24 @@ func main() {
 		v1.POST("/orders", ordersHandler.CreateOrder)
 		v1.GET("/orders", ordersHandler.ListOrders)
 		v1.GET("/orders/:id", ordersHandler.GetOrder)
+		v1.PATCH("/orders/:id", ordersHandler.UpdateOrderItems)
+		v1.DELETE("/orders/:id", ordersHandler.CancelOrder)
+		v1.POST("/orders/:id/transitions", ordersHandler.TransitionOrder)
+		v1.POST("/users/:id/orders:cancel", ordersHandler.CancelOrdersForUser)
+		v1.GET("/orders/:id/event-log", ordersHandler.ListOrderEvents)
+		v1.POST("/admin/reconcile", reconcileHandler.Reconcile)
+		if eventStream != nil {
+			eventsHandler := handlers.NewOrderEventsHandlerWithVerifier(eventStream, logger, verifier)
+			v1.GET("/orders/:id/events", eventsHandler.Subscribe)
+			v1.GET("/orders/stream", eventsHandler.SubscribeAll)
+		}
 	}
 
+	r.GET("/healthz", healthHandler.Healthz)
+	r.GET("/readyz", healthHandler.Readyz)
 	r.GET("/health", healthHandler.Health)
 	r.GET("/metrics", metrics.Handler())
+	r.GET("/debug/circuit", debugHandler.Circuit)
 
 	// Server setup
 	srv := &http.Server{
@@ -118,6 +384,14 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	healthHandler.MarkShuttingDown()
+
+	if sweeperCancel != nil {
+		sweeperCancel()
+	}
+	dispatcherCancel()
+	reconcilerCancel()
+	healthCancel()
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
@@ -127,5 +401,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if telemetryShutdown != nil {
+		if err := telemetryShutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down telemetry", slog.String("error", err.Error()))
+		}
+	}
+
 	logger.Info("Server exited")
 }
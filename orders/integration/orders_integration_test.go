@@ -6,6 +6,7 @@ package integration
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -25,6 +26,8 @@ import (
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/repository"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/service"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/service/pricing"
+	"github.com/shopspring/decimal"
 )
 
 // minimal fake books client
@@ -83,6 +86,19 @@ func setupDB(t *testing.T) (*pgxpool.Pool, func()) {
 	schema := `CREATE TABLE IF NOT EXISTS orders (
         id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
         total_price NUMERIC(10,2) NOT NULL,
+        currency TEXT NOT NULL DEFAULT 'USD',
+        fx_rate NUMERIC(20,10) NOT NULL DEFAULT 1,
+        base_total_price NUMERIC(10,2) NOT NULL DEFAULT 0,
+        status TEXT NOT NULL DEFAULT 'pending',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+    CREATE TABLE IF NOT EXISTS order_status_audit (
+        id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+        order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+        from_status TEXT NOT NULL,
+        to_status TEXT NOT NULL,
+        reason TEXT NOT NULL DEFAULT '',
         created_at TIMESTAMPTZ NOT NULL DEFAULT now()
     );
     CREATE TABLE IF NOT EXISTS order_items (
@@ -94,6 +110,10 @@ func setupDB(t *testing.T) (*pgxpool.Pool, func()) {
         quantity INTEGER NOT NULL,
         unit_price NUMERIC(10,2) NOT NULL,
         total_price NUMERIC(10,2) NOT NULL,
+        currency TEXT NOT NULL DEFAULT 'USD',
+        fx_rate NUMERIC(20,10) NOT NULL DEFAULT 1,
+        base_unit_price NUMERIC(10,2) NOT NULL DEFAULT 0,
+        base_total_price NUMERIC(10,2) NOT NULL DEFAULT 0,
         created_at TIMESTAMPTZ NOT NULL DEFAULT now()
     );
     CREATE TABLE IF NOT EXISTS idempotency_keys (
@@ -101,6 +121,30 @@ func setupDB(t *testing.T) (*pgxpool.Pool, func()) {
         order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
         request_hash TEXT NOT NULL,
         created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+    CREATE TABLE IF NOT EXISTS idempotency_responses (
+        key TEXT PRIMARY KEY,
+        request_hash TEXT NOT NULL,
+        response_body BYTEA NOT NULL,
+        status_code INTEGER NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        expires_at TIMESTAMPTZ NOT NULL
+    );
+    CREATE TABLE IF NOT EXISTS order_anomalies (
+        id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+        order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+        book_id BIGINT NOT NULL,
+        anomaly_type TEXT NOT NULL,
+        detail TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+    CREATE TABLE IF NOT EXISTS order_adjustments (
+        id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+        order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+        kind TEXT NOT NULL,
+        label TEXT NOT NULL,
+        amount NUMERIC(10,2) NOT NULL,
+        sort_order INTEGER NOT NULL DEFAULT 0
     );`
 	_, err = pool.Exec(ctx, schema)
 	require.NoError(t, err)
@@ -113,8 +157,33 @@ func newOrdersRouter(t *testing.T, pool *pgxpool.Pool) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	repo := repository.NewOrdersRepository(pool)
-	cfg := &config.Config{IdempotencyEnabled: true}
-	svc := service.NewOrdersService(repo, &fakeBooksClient{price: "19.99"}, logger, cfg)
+	cfg := &config.Config{IdempotencyEnabled: true, PriceRoundingMode: models.RoundHalfEven}
+	svc := service.NewOrdersService(service.WithRepository(repo), service.WithBooksClient(&fakeBooksClient{price: "19.99"}), service.WithLogger(logger), service.WithConfig(cfg))
+	h := handlers.NewOrdersHandler(svc, logger)
+	r := gin.New()
+	v1 := r.Group("/v1")
+	v1.POST("/orders", h.CreateOrder)
+	v1.GET("/orders", h.ListOrders)
+	v1.GET("/orders/:id", h.GetOrder)
+	return r
+}
+
+// newOrdersRouterWithDiscounts is newOrdersRouter plus a pricing pipeline and
+// a registered discount code, for tests that exercise discount_code actually
+// being applied rather than just accepted.
+func newOrdersRouterWithDiscounts(t *testing.T, pool *pgxpool.Pool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.NewOrdersRepository(pool)
+	cfg := &config.Config{IdempotencyEnabled: true, PriceRoundingMode: models.RoundHalfEven}
+	svc := service.NewOrdersService(
+		service.WithRepository(repo),
+		service.WithBooksClient(&fakeBooksClient{price: "19.99"}),
+		service.WithLogger(logger),
+		service.WithConfig(cfg),
+		service.WithPricingPipeline(&pricing.Pipeline{}),
+		service.WithDiscountCodes(map[string]decimal.Decimal{"SAVE10": decimal.RequireFromString("0.10")}),
+	)
 	h := handlers.NewOrdersHandler(svc, logger)
 	r := gin.New()
 	v1 := r.Group("/v1")
@@ -124,6 +193,58 @@ func newOrdersRouter(t *testing.T, pool *pgxpool.Pool) *gin.Engine {
 	return r
 }
 
+// TestIntegration_DiscountCodeAppliesEndToEnd covers discount_code actually
+// discounting an order end-to-end (not just being accepted by idempotency
+// hashing), and covers the fail-closed behavior for a deployment that hasn't
+// wired a pricing pipeline at all.
+func TestIntegration_DiscountCodeAppliesEndToEnd(t *testing.T) {
+	pool, cleanup := setupDB(t)
+	defer cleanup()
+
+	// 2 x 19.99 = 39.98, SAVE10 takes 10% off -> 35.98.
+	body := `{"items":[{"book_id":1,"quantity":2}],"discount_code":"SAVE10"}`
+
+	router := newOrdersRouterWithDiscounts(t, pool)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "discount-1")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, "35.98", created.TotalPrice)
+	require.Len(t, created.Adjustments, 1)
+	require.Equal(t, pricing.KindDiscount, created.Adjustments[0].Kind)
+	require.Equal(t, "-4.00", created.Adjustments[0].Amount)
+
+	// Adjustments must survive a GET after create, not just the create
+	// response itself - they're written in the same transaction as the order
+	// but read back by a separate query in GetOrderByID.
+	wGet := httptest.NewRecorder()
+	reqGet, _ := http.NewRequest("GET", fmt.Sprintf("/v1/orders/%d", created.ID), nil)
+	router.ServeHTTP(wGet, reqGet)
+	require.Equal(t, http.StatusOK, wGet.Code, wGet.Body.String())
+
+	var fetched models.Order
+	require.NoError(t, json.Unmarshal(wGet.Body.Bytes(), &fetched))
+	require.Equal(t, "35.98", fetched.TotalPrice)
+	require.Len(t, fetched.Adjustments, 1)
+	require.Equal(t, pricing.KindDiscount, fetched.Adjustments[0].Kind)
+	require.Equal(t, "-4.00", fetched.Adjustments[0].Amount)
+
+	// A deployment that never wired WithPricingPipeline must reject
+	// discount_code rather than silently create a full-price order.
+	plainRouter := newOrdersRouter(t, pool)
+	w2 := httptest.NewRecorder()
+	req2, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(body))
+	req2.Header.Set("Content-Type", "application/json")
+	req2.Header.Set("Idempotency-Key", "discount-2")
+	plainRouter.ServeHTTP(w2, req2)
+	require.Equal(t, http.StatusServiceUnavailable, w2.Code, w2.Body.String())
+}
+
 func TestIntegration_OrderIdempotencyAndPagination(t *testing.T) {
 	pool, cleanup := setupDB(t)
 	defer cleanup()
@@ -137,6 +258,7 @@ func TestIntegration_OrderIdempotencyAndPagination(t *testing.T) {
 	req1.Header.Set("Idempotency-Key", "k1")
 	router.ServeHTTP(w1, req1)
 	require.Equal(t, http.StatusCreated, w1.Code, w1.Body.String())
+	require.Equal(t, "half_even", w1.Header().Get("X-Rounding-Mode"))
 
 	// Replay same request with same key -> reused order
 	w2 := httptest.NewRecorder()
@@ -156,6 +278,17 @@ func TestIntegration_OrderIdempotencyAndPagination(t *testing.T) {
 	router.ServeHTTP(w3, req3)
 	require.Equal(t, http.StatusConflict, w3.Code)
 
+	// Same items, same key, only discount_code differs -> conflict. The
+	// idempotency request hash covers discount_code precisely so this can't
+	// silently replay the first request's (discount-less) order.
+	discountBody := `{"items":[{"book_id":1,"quantity":2}],"discount_code":"SAVE10"}`
+	w3b := httptest.NewRecorder()
+	req3b, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(discountBody))
+	req3b.Header.Set("Content-Type", "application/json")
+	req3b.Header.Set("Idempotency-Key", "k1")
+	router.ServeHTTP(w3b, req3b)
+	require.Equal(t, http.StatusConflict, w3b.Code)
+
 	// Create a bunch more orders for pagination
 	for i := 0; i < 3; i++ { // keep small for speed
 		w := httptest.NewRecorder()
@@ -178,3 +311,106 @@ func TestIntegration_OrderIdempotencyAndPagination(t *testing.T) {
 		t.Fatalf("expected X-Total-Count header")
 	}
 }
+
+// TestIntegration_CursorPaginationWalksWithoutDuplicatesOrGaps inserts 50
+// orders, then walks the whole list through ?cursor= pages (including one
+// concurrent insert mid-walk) and asserts every seeded order is seen exactly
+// once, while the order inserted mid-walk, being newer than every cursor
+// position, is not returned by the forward walk.
+// It also checks that a cursor whose signature has been tampered with is
+// rejected with 400 rather than accepted or 5xx'd.
+func TestIntegration_CursorPaginationWalksWithoutDuplicatesOrGaps(t *testing.T) {
+	pool, cleanup := setupDB(t)
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.NewOrdersRepository(pool)
+	cfg := &config.Config{PriceRoundingMode: models.RoundHalfEven, CursorSigningSecret: "cursor-secret"}
+	svc := service.NewOrdersService(service.WithRepository(repo), service.WithBooksClient(&fakeBooksClient{price: "9.99"}), service.WithLogger(logger), service.WithConfig(cfg))
+	h := handlers.NewOrdersHandler(svc, logger)
+	r := gin.New()
+	v1 := r.Group("/v1")
+	v1.POST("/orders", h.CreateOrder)
+	v1.GET("/orders", h.ListOrders)
+
+	createOrder := func(key string) int64 {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(`{"items":[{"book_id":1,"quantity":1}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var created models.Order
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created.ID
+	}
+
+	const seeded = 50
+	seen := make(map[int64]bool, seeded)
+	for i := 0; i < seeded; i++ {
+		id := createOrder(fmt.Sprintf("seed-%d-%d", i, time.Now().UnixNano()))
+		seen[id] = true
+	}
+
+	walked := make(map[int64]bool, seeded)
+	cursor := ""
+	insertedMidWalk := false
+	var concurrentID int64
+	for page := 0; ; page++ {
+		target := "/v1/orders?limit=7"
+		if cursor != "" {
+			target = fmt.Sprintf("/v1/orders?limit=7&cursor=%s", cursor)
+		}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", target, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp models.CursorPaginationResponse[*models.Order]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		for _, o := range resp.Data {
+			require.False(t, walked[o.ID], "order %d seen twice while walking cursor pages", o.ID)
+			walked[o.ID] = true
+		}
+
+		// Halfway through the walk, insert one more order concurrently. It
+		// was created after this page's snapshot, so it must not appear as
+		// a duplicate or knock any already-seen order out of the result.
+		if !insertedMidWalk && page == 3 {
+			insertedMidWalk = true
+			concurrentID = createOrder(fmt.Sprintf("concurrent-%d", time.Now().UnixNano()))
+		}
+
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+
+		require.Less(t, page, seeded+10, "cursor walk did not terminate")
+	}
+
+	require.True(t, insertedMidWalk)
+	for id := range seen {
+		require.True(t, walked[id], "order %d inserted before the walk was never returned", id)
+	}
+	require.False(t, walked[concurrentID], "order %d inserted mid-walk is newer than every cursor position and must not appear", concurrentID)
+
+	// Tamper with the last cursor's signature: flipping the final character
+	// must invalidate the HMAC tag without producing a different, still-valid
+	// cursor by chance.
+	tampered := cursor
+	if tampered == "" {
+		tampered = "not-a-real-cursor.deadbeef"
+	} else {
+		flipped := byte('a')
+		if tampered[len(tampered)-1] == 'a' {
+			flipped = 'b'
+		}
+		tampered = tampered[:len(tampered)-1] + string(flipped)
+	}
+	wb := httptest.NewRecorder()
+	reqb, _ := http.NewRequest("GET", fmt.Sprintf("/v1/orders?limit=7&cursor=%s", tampered), nil)
+	r.ServeHTTP(wb, reqb)
+	require.Equal(t, http.StatusBadRequest, wb.Code, wb.Body.String())
+}
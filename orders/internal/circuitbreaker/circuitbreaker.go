@@ -0,0 +1,227 @@
+// Package circuitbreaker implements a reusable closed/open/half-open circuit
+// breaker that any outbound client (Books HTTP, future gRPC/queue clients,
+// etc.) can wrap itself with.
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is one of the three breaker states.
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Metrics is implemented by callers that want Prometheus (or any other)
+// visibility into breaker state changes.
+type Metrics interface {
+	SetCircuitState(state string)
+	IncCircuitTransition(from, to string)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) SetCircuitState(string)       {}
+func (noopMetrics) IncCircuitTransition(_, _ string) {}
+
+// Config controls breaker thresholds and backoff behavior.
+type Config struct {
+	// FailureThreshold is the number of consecutive failures in Closed state
+	// before the breaker opens.
+	FailureThreshold int
+	// SuccessThreshold is the number of consecutive successful probes in
+	// HalfOpen state required to close the breaker again.
+	SuccessThreshold int
+	// ProbeLimit caps how many concurrent requests are allowed through while
+	// HalfOpen; all other callers are rejected until a probe resolves.
+	ProbeLimit int
+	// BaseCooldown is the initial Open duration before the breaker allows a
+	// probe. Each time a probe fails (re-opening the breaker) the cooldown
+	// doubles, capped at MaxCooldown.
+	BaseCooldown time.Duration
+	MaxCooldown  time.Duration
+}
+
+// DefaultConfig mirrors the thresholds the old two-state breaker used.
+func DefaultConfig() Config {
+	return Config{
+		FailureThreshold: 5,
+		SuccessThreshold: 2,
+		ProbeLimit:       1,
+		BaseCooldown:     30 * time.Second,
+		MaxCooldown:      5 * time.Minute,
+	}
+}
+
+// Breaker is a closed -> open -> half-open state machine safe for concurrent use.
+type Breaker struct {
+	cfg     Config
+	metrics Metrics
+
+	mu                sync.Mutex
+	state             State
+	failureCount      int
+	probeSuccesses    int
+	probesInFlight    int
+	consecutiveOpens  int
+	openedAt          time.Time
+	lastTransitionAt  time.Time
+}
+
+// New creates a Breaker. Pass nil for metrics to disable emission.
+func New(cfg Config, metrics Metrics) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultConfig().FailureThreshold
+	}
+	if cfg.SuccessThreshold <= 0 {
+		cfg.SuccessThreshold = DefaultConfig().SuccessThreshold
+	}
+	if cfg.ProbeLimit <= 0 {
+		cfg.ProbeLimit = DefaultConfig().ProbeLimit
+	}
+	if cfg.BaseCooldown <= 0 {
+		cfg.BaseCooldown = DefaultConfig().BaseCooldown
+	}
+	if cfg.MaxCooldown <= 0 {
+		cfg.MaxCooldown = DefaultConfig().MaxCooldown
+	}
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
+	b := &Breaker{cfg: cfg, metrics: metrics, lastTransitionAt: time.Now()}
+	metrics.SetCircuitState(Closed.String())
+	return b
+}
+
+// Allow reports whether the caller may issue the request now. It also drives
+// the Open -> HalfOpen transition once the cooldown has elapsed.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		return true
+	case Open:
+		if time.Since(b.openedAt) < b.cooldown() {
+			return false
+		}
+		b.transitionLocked(HalfOpen)
+		b.probesInFlight = 1
+		return true
+	case HalfOpen:
+		if b.probesInFlight >= b.cfg.ProbeLimit {
+			return false
+		}
+		b.probesInFlight++
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess reports a successful call made after Allow returned true.
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case HalfOpen:
+		b.probesInFlight--
+		b.probeSuccesses++
+		if b.probeSuccesses >= b.cfg.SuccessThreshold {
+			b.consecutiveOpens = 0
+			b.failureCount = 0
+			b.probeSuccesses = 0
+			b.transitionLocked(Closed)
+		}
+	case Closed:
+		b.failureCount = 0
+	}
+}
+
+// RecordFailure reports a failed call made after Allow returned true.
+func (b *Breaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case HalfOpen:
+		b.probesInFlight--
+		b.probeSuccesses = 0
+		b.consecutiveOpens++
+		b.openedAt = time.Now()
+		b.transitionLocked(Open)
+	case Closed:
+		b.failureCount++
+		if b.failureCount >= b.cfg.FailureThreshold {
+			b.consecutiveOpens = 1
+			b.openedAt = time.Now()
+			b.transitionLocked(Open)
+		}
+	}
+}
+
+// cooldown returns the current backoff, doubling per consecutive re-open and
+// capped at MaxCooldown. Must be called with mu held.
+func (b *Breaker) cooldown() time.Duration {
+	opens := b.consecutiveOpens
+	if opens < 1 {
+		opens = 1
+	}
+	d := b.cfg.BaseCooldown
+	for i := 1; i < opens && d < b.cfg.MaxCooldown; i++ {
+		d *= 2
+	}
+	if d > b.cfg.MaxCooldown {
+		d = b.cfg.MaxCooldown
+	}
+	return d
+}
+
+func (b *Breaker) transitionLocked(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	b.lastTransitionAt = time.Now()
+	b.metrics.SetCircuitState(to.String())
+	b.metrics.IncCircuitTransition(from.String(), to.String())
+}
+
+// Snapshot is a point-in-time view of breaker state, useful for diagnostic
+// endpoints.
+type Snapshot struct {
+	State            string    `json:"state"`
+	LastTransitionAt time.Time `json:"last_transition_at"`
+	FailureCount     int       `json:"failure_count"`
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Snapshot{
+		State:            b.state.String(),
+		LastTransitionAt: b.lastTransitionAt,
+		FailureCount:     b.failureCount,
+	}
+}
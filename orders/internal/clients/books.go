@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,35 +9,59 @@ import (
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/circuitbreaker"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer names spans this package starts; it's a no-op tracer until
+// telemetry.Setup registers a real TracerProvider.
+var tracer = otel.Tracer("github.com/infinity-9427/bookstore-microservices/orders/internal/clients")
+
+// injectTraceparent adds a W3C traceparent header for ctx's span onto req,
+// so the Books service can continue the same trace. A no-op when no
+// TracerProvider is configured.
+func injectTraceparent(ctx context.Context, req *http.Request) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
 // Metrics interface for Books client
 type BooksMetrics interface {
 	IncBooksRequest(result string)
 	ObserveBooksLatency(duration time.Duration)
+	SetCircuitState(state string)
+	IncCircuitTransition(from, to string)
+	ObserveBooksBatchSize(size int)
 }
 
 type BooksClient interface {
 	GetBook(ctx context.Context, bookID int64) (*models.Book, error)
 	GetBooks(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error)
+	// Ping is a lightweight liveness probe for the Books upstream, meant to be
+	// called on a timer by a health checker rather than per request - unlike
+	// GetBook/GetBooks it doesn't touch the circuit breaker or metrics, since
+	// it isn't serving real traffic.
+	Ping(ctx context.Context) error
 }
 
 type HTTPBooksClient struct {
-	http    *http.Client
-	base    string
-	logger  *slog.Logger
-	metrics BooksMetrics
-
-	// Circuit breaker state
-	circuitMutex    sync.RWMutex
-	circuitOpen     bool
-	circuitOpenTime time.Time
-	failureCount    int
-	threshold       int
-	cooldownPeriod  time.Duration
+	http     *http.Client
+	base     string
+	logger   *slog.Logger
+	metrics  BooksMetrics
+	breaker  *circuitbreaker.Breaker
+	batchMax int
+
+	// batchUnsupported is set once the upstream answers the batch route with
+	// 404/405, so subsequent calls skip straight to per-ID fetches instead of
+	// re-probing every time.
+	batchUnsupported atomic.Bool
 }
 
 type CircuitBreakerError struct {
@@ -75,12 +100,25 @@ type simpleMetrics struct{}
 
 func (m *simpleMetrics) IncBooksRequest(result string)              {}
 func (m *simpleMetrics) ObserveBooksLatency(duration time.Duration) {}
+func (m *simpleMetrics) SetCircuitState(state string)               {}
+func (m *simpleMetrics) IncCircuitTransition(from, to string)       {}
+func (m *simpleMetrics) ObserveBooksBatchSize(size int)             {}
 
 func NewHTTPBooksClient(base string, timeout time.Duration, logger *slog.Logger) *HTTPBooksClient {
 	return NewHTTPBooksClientWithMetrics(base, timeout, logger, &simpleMetrics{})
 }
 
 func NewHTTPBooksClientWithMetrics(base string, timeout time.Duration, logger *slog.Logger, metrics BooksMetrics) *HTTPBooksClient {
+	return NewHTTPBooksClientWithBatchMax(base, timeout, logger, metrics, defaultBatchMax)
+}
+
+// NewHTTPBooksClientWithBatchMax is like NewHTTPBooksClientWithMetrics but
+// lets callers override how many book IDs are fetched per batchGet request
+// (config.Config.BooksBatchMax).
+func NewHTTPBooksClientWithBatchMax(base string, timeout time.Duration, logger *slog.Logger, metrics BooksMetrics, batchMax int) *HTTPBooksClient {
+	if batchMax <= 0 {
+		batchMax = defaultBatchMax
+	}
 	return &HTTPBooksClient{
 		http: &http.Client{
 			Timeout: timeout,
@@ -90,50 +128,64 @@ func NewHTTPBooksClientWithMetrics(base string, timeout time.Duration, logger *s
 				IdleConnTimeout:     30 * time.Second,
 			},
 		},
-		base:           base,
-		logger:         logger,
-		metrics:        metrics,
-		threshold:      5,
-		cooldownPeriod: 30 * time.Second,
+		base:     base,
+		logger:   logger,
+		metrics:  metrics,
+		breaker:  circuitbreaker.New(circuitbreaker.DefaultConfig(), metrics),
+		batchMax: batchMax,
 	}
 }
 
-func (c *HTTPBooksClient) isCircuitOpen() bool {
-	c.circuitMutex.RLock()
-	defer c.circuitMutex.RUnlock()
-
-	if !c.circuitOpen {
-		return false
-	}
+const defaultBatchMax = 100
 
-	// Check if cooldown period has passed
-	if time.Since(c.circuitOpenTime) > c.cooldownPeriod {
-		return false // Allow one request to test service health
-	}
+// CircuitSnapshot exposes the breaker's current state for diagnostic endpoints.
+func (c *HTTPBooksClient) CircuitSnapshot() circuitbreaker.Snapshot {
+	return c.breaker.Snapshot()
+}
 
-	return true
+func (c *HTTPBooksClient) isCircuitOpen() bool {
+	return !c.breaker.Allow()
 }
 
 func (c *HTTPBooksClient) recordSuccess() {
-	c.circuitMutex.Lock()
-	defer c.circuitMutex.Unlock()
+	c.breaker.RecordSuccess()
+}
+
+// pingProbeBookID is a sentinel ID Ping requests: Books answering with
+// either the book or a 404 both prove the upstream is reachable and
+// responding, so either is treated as a successful probe.
+const pingProbeBookID = 99999
+
+// Ping is a cheap reachability probe for a health checker's background
+// refresh loop. It deliberately bypasses the circuit breaker and metrics -
+// it isn't real traffic, and a checker calling it on a timer shouldn't be
+// able to trip (or be blocked by) the breaker that guards GetBook/GetBooks.
+func (c *HTTPBooksClient) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("%s/v1/books/%d", c.base, pingProbeBookID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "orders-service/1.0")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return &ServiceUnavailableError{Message: "Books service unavailable"}
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
 
-	c.failureCount = 0
-	c.circuitOpen = false
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return &ServiceUnavailableError{Message: fmt.Sprintf("Books service returned status %d", resp.StatusCode)}
 }
 
 func (c *HTTPBooksClient) recordFailure() {
-	c.circuitMutex.Lock()
-	defer c.circuitMutex.Unlock()
-
-	c.failureCount++
-	if c.failureCount >= c.threshold {
-		c.circuitOpen = true
-		c.circuitOpenTime = time.Now()
-		c.logger.Warn("Books service circuit breaker opened",
-			slog.Int("failure_count", c.failureCount),
-			slog.Int("threshold", c.threshold))
-	}
+	c.breaker.RecordFailure()
 }
 
 func (c *HTTPBooksClient) GetBook(ctx context.Context, id int64) (*models.Book, error) {
@@ -172,6 +224,7 @@ func (c *HTTPBooksClient) GetBook(ctx context.Context, id int64) (*models.Book,
 	if requestID != nil {
 		req.Header.Set("X-Request-ID", fmt.Sprintf("%v", requestID))
 	}
+	injectTraceparent(ctx, req)
 
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -248,12 +301,164 @@ func (c *HTTPBooksClient) GetBook(ctx context.Context, id int64) (*models.Book,
 	}
 }
 
-// GetBooks retrieves multiple books concurrently with limited parallelism
+// GetBooks retrieves multiple books, preferring a single batchGet round trip
+// and falling back to per-ID concurrent fetches if the upstream doesn't
+// support the batch route (404/405, detected once and cached).
 func (c *HTTPBooksClient) GetBooks(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
+	ctx, span := tracer.Start(ctx, "HTTPBooksClient.GetBooks", trace.WithAttributes(attribute.Int("book_count", len(bookIDs))))
+	defer span.End()
+
 	if len(bookIDs) == 0 {
 		return make(map[int64]*models.Book), nil
 	}
 
+	// Dedupe in case a multi-item order references the same book twice.
+	seen := make(map[int64]struct{}, len(bookIDs))
+	uniqueIDs := make([]int64, 0, len(bookIDs))
+	for _, id := range bookIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		uniqueIDs = append(uniqueIDs, id)
+	}
+
+	if !c.batchUnsupported.Load() {
+		books, err := c.getBooksBatch(ctx, uniqueIDs)
+		if err == nil {
+			return books, nil
+		}
+		if _, unsupported := err.(*batchUnsupportedError); !unsupported {
+			return nil, err
+		}
+		c.batchUnsupported.Store(true)
+		c.logger.WarnContext(ctx, "Books batchGet route unsupported by upstream, falling back to per-ID fetches")
+	}
+
+	return c.getBooksConcurrent(ctx, uniqueIDs)
+}
+
+// batchUnsupportedError signals that the upstream doesn't implement the
+// batchGet route (404/405), distinct from a genuine request failure.
+type batchUnsupportedError struct{ status int }
+
+func (e *batchUnsupportedError) Error() string {
+	return fmt.Sprintf("batchGet route returned status %d", e.status)
+}
+
+// getBooksBatch fetches books via POST /v1/books:batchGet, chunking requests
+// larger than batchMax and merging the results.
+func (c *HTTPBooksClient) getBooksBatch(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
+	books := make(map[int64]*models.Book, len(bookIDs))
+
+	for start := 0; start < len(bookIDs); start += c.batchMax {
+		end := start + c.batchMax
+		if end > len(bookIDs) {
+			end = len(bookIDs)
+		}
+		chunk := bookIDs[start:end]
+
+		c.metrics.ObserveBooksBatchSize(len(chunk))
+
+		chunkBooks, err := c.batchGetOnce(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		for id, b := range chunkBooks {
+			books[id] = b
+		}
+	}
+
+	return books, nil
+}
+
+type batchGetRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+func (c *HTTPBooksClient) batchGetOnce(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveBooksLatency(time.Since(start)) }()
+
+	if c.isCircuitOpen() {
+		c.metrics.IncBooksRequest("circuit_open")
+		return nil, &CircuitBreakerError{Message: "Books service circuit breaker is open"}
+	}
+
+	url := fmt.Sprintf("%s/v1/books:batchGet", c.base)
+	body, err := json.Marshal(batchGetRequest{IDs: bookIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode batch request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		c.recordFailure()
+		c.metrics.IncBooksRequest("client_error")
+		return nil, fmt.Errorf("failed to create batch request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "orders-service/1.0")
+	injectTraceparent(ctx, req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.recordFailure()
+		c.metrics.IncBooksRequest("timeout")
+		return nil, &ServiceUnavailableError{Message: "Books service unavailable"}
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var result []models.Book
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			c.recordFailure()
+			c.metrics.IncBooksRequest("client_error")
+			return nil, fmt.Errorf("failed to decode batch response: %w", err)
+		}
+		c.recordSuccess()
+
+		books := make(map[int64]*models.Book, len(result))
+		for i := range result {
+			book := result[i]
+			if !book.Active {
+				c.metrics.IncBooksRequest("inactive")
+				return nil, &BookInactiveError{BookID: book.ID}
+			}
+			books[book.ID] = &book
+		}
+		for _, id := range bookIDs {
+			if _, found := books[id]; !found {
+				c.metrics.IncBooksRequest("not_found")
+				return nil, &BookNotFoundError{BookID: id}
+			}
+		}
+		c.metrics.IncBooksRequest("active")
+		return books, nil
+
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		// The upstream doesn't implement batchGet; fall back to per-ID calls
+		// without counting this as a circuit breaker failure.
+		return nil, &batchUnsupportedError{status: resp.StatusCode}
+
+	default:
+		if resp.StatusCode >= 500 {
+			c.recordFailure()
+			c.metrics.IncBooksRequest("upstream_error")
+			return nil, &ServiceUnavailableError{Message: fmt.Sprintf("Books service returned status %d", resp.StatusCode)}
+		}
+		c.recordSuccess()
+		c.metrics.IncBooksRequest("client_error")
+		return nil, fmt.Errorf("books API returned status %d", resp.StatusCode)
+	}
+}
+
+// getBooksConcurrent retrieves multiple books concurrently with limited parallelism.
+func (c *HTTPBooksClient) getBooksConcurrent(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
 	// Limit concurrency to avoid overwhelming the Books service
 	const maxConcurrency = 5
 	semaphore := make(chan struct{}, maxConcurrency)
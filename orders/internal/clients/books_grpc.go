@@ -0,0 +1,164 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/circuitbreaker"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/clients/bookspb"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+)
+
+// GRPCBooksClient talks to the Books service over gRPC instead of HTTP,
+// implementing the same BooksClient interface, error types, and metrics
+// hooks as HTTPBooksClient so callers (and the circuit breaker) can't tell
+// the difference.
+type GRPCBooksClient struct {
+	client  bookspb.BooksClient
+	conn    *grpc.ClientConn
+	metrics BooksMetrics
+	breaker *circuitbreaker.Breaker
+}
+
+// NewGRPCBooksClient wraps an existing connection to the Books gRPC endpoint
+// in the same circuit breaker semantics HTTPBooksClient uses.
+func NewGRPCBooksClient(conn *grpc.ClientConn, metrics BooksMetrics) *GRPCBooksClient {
+	if metrics == nil {
+		metrics = &simpleMetrics{}
+	}
+	return &GRPCBooksClient{
+		client:  bookspb.NewBooksClient(conn),
+		conn:    conn,
+		metrics: metrics,
+		breaker: circuitbreaker.New(circuitbreaker.DefaultConfig(), metrics),
+	}
+}
+
+func (c *GRPCBooksClient) Close() error {
+	return c.conn.Close()
+}
+
+// CircuitSnapshot exposes the breaker's current state for diagnostic endpoints.
+func (c *GRPCBooksClient) CircuitSnapshot() circuitbreaker.Snapshot {
+	return c.breaker.Snapshot()
+}
+
+func (c *GRPCBooksClient) GetBook(ctx context.Context, id int64) (*models.Book, error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveBooksLatency(time.Since(start)) }()
+
+	if !c.breaker.Allow() {
+		c.metrics.IncBooksRequest("circuit_open")
+		return nil, &CircuitBreakerError{Message: "Books service circuit breaker is open"}
+	}
+
+	resp, err := c.client.GetBook(ctx, &bookspb.GetBookRequest{Id: id})
+	if err != nil {
+		return nil, c.mapError(err, id)
+	}
+
+	c.breaker.RecordSuccess()
+	if !resp.Active {
+		c.metrics.IncBooksRequest("inactive")
+		return nil, &BookInactiveError{BookID: id}
+	}
+	c.metrics.IncBooksRequest("active")
+	return bookFromProto(resp), nil
+}
+
+func (c *GRPCBooksClient) GetBooks(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
+	if len(bookIDs) == 0 {
+		return make(map[int64]*models.Book), nil
+	}
+
+	start := time.Now()
+	defer func() { c.metrics.ObserveBooksLatency(time.Since(start)) }()
+
+	if !c.breaker.Allow() {
+		c.metrics.IncBooksRequest("circuit_open")
+		return nil, &CircuitBreakerError{Message: "Books service circuit breaker is open"}
+	}
+
+	resp, err := c.client.BatchGetBooks(ctx, &bookspb.BatchGetBooksRequest{Ids: bookIDs})
+	if err != nil {
+		return nil, c.mapError(err, bookIDs[0])
+	}
+	c.breaker.RecordSuccess()
+
+	if len(resp.MissingIds) > 0 {
+		c.metrics.IncBooksRequest("not_found")
+		return nil, &BookNotFoundError{BookID: resp.MissingIds[0]}
+	}
+
+	books := make(map[int64]*models.Book, len(resp.Books))
+	for _, b := range resp.Books {
+		if !b.Active {
+			c.metrics.IncBooksRequest("inactive")
+			return nil, &BookInactiveError{BookID: b.Id}
+		}
+		books[b.Id] = bookFromProto(b)
+	}
+	c.metrics.IncBooksRequest("active")
+	return books, nil
+}
+
+// Ping is a cheap reachability probe for a health checker's background
+// refresh loop: it bypasses the circuit breaker and metrics the same way
+// HTTPBooksClient.Ping does, since a timer-driven probe isn't real traffic.
+func (c *GRPCBooksClient) Ping(ctx context.Context) error {
+	_, err := c.client.GetBook(ctx, &bookspb.GetBookRequest{Id: pingProbeBookID})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+			return nil
+		}
+		return fmt.Errorf("books service unreachable: %w", err)
+	}
+	return nil
+}
+
+// mapError translates gRPC status codes to the same error types the HTTP
+// client returns, so downstream service code doesn't need to know which
+// transport answered the request.
+func (c *GRPCBooksClient) mapError(err error, bookID int64) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.breaker.RecordFailure()
+		c.metrics.IncBooksRequest("timeout")
+		return &ServiceUnavailableError{Message: "Books service unavailable: " + err.Error()}
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		c.breaker.RecordSuccess() // not found is a valid response, not a failure
+		c.metrics.IncBooksRequest("not_found")
+		return &BookNotFoundError{BookID: bookID}
+	case codes.FailedPrecondition:
+		c.breaker.RecordSuccess()
+		c.metrics.IncBooksRequest("inactive")
+		return &BookInactiveError{BookID: bookID}
+	case codes.Unavailable, codes.DeadlineExceeded:
+		c.breaker.RecordFailure()
+		c.metrics.IncBooksRequest("timeout")
+		return &ServiceUnavailableError{Message: fmt.Sprintf("Books service unavailable: %s", st.Message())}
+	default:
+		c.breaker.RecordFailure()
+		c.metrics.IncBooksRequest("upstream_error")
+		return &ServiceUnavailableError{Message: fmt.Sprintf("Books service error (%s): %s", st.Code(), st.Message())}
+	}
+}
+
+func bookFromProto(b *bookspb.Book) *models.Book {
+	return &models.Book{
+		ID:          b.Id,
+		Title:       b.Title,
+		Author:      b.Author,
+		Description: b.Description,
+		Price:       b.Price,
+		Active:      b.Active,
+	}
+}
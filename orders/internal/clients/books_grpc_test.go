@@ -0,0 +1,115 @@
+package clients
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/clients/bookspb"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// fakeBooksServer is a minimal in-process implementation of the Books gRPC
+// service for unit tests.
+type fakeBooksServer struct {
+	bookspb.UnimplementedBooksServer
+	books map[int64]*bookspb.Book
+}
+
+func (s *fakeBooksServer) GetBook(ctx context.Context, req *bookspb.GetBookRequest) (*bookspb.Book, error) {
+	if b, ok := s.books[req.Id]; ok {
+		return b, nil
+	}
+	return nil, status.Error(codes.NotFound, "book not found")
+}
+
+func (s *fakeBooksServer) BatchGetBooks(ctx context.Context, req *bookspb.BatchGetBooksRequest) (*bookspb.BatchGetBooksResponse, error) {
+	resp := &bookspb.BatchGetBooksResponse{}
+	for _, id := range req.Ids {
+		if b, ok := s.books[id]; ok {
+			resp.Books = append(resp.Books, b)
+		} else {
+			resp.MissingIds = append(resp.MissingIds, id)
+		}
+	}
+	return resp, nil
+}
+
+func newBufconnClient(t *testing.T, srv *fakeBooksServer) *GRPCBooksClient {
+	t.Helper()
+	const bufSize = 1024 * 1024
+	lis := bufconn.Listen(bufSize)
+
+	s := grpc.NewServer()
+	bookspb.RegisterBooksServer(s, srv)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewGRPCBooksClient(conn, &simpleMetrics{})
+}
+
+func TestGRPCBooksClient_GetBook_Found(t *testing.T) {
+	srv := &fakeBooksServer{books: map[int64]*bookspb.Book{
+		1: {Id: 1, Title: "Go in Action", Author: "W. Kennedy", Price: "29.99", Active: true},
+	}}
+	client := newBufconnClient(t, srv)
+
+	book, err := client.GetBook(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if book.Title != "Go in Action" {
+		t.Errorf("expected title %q, got %q", "Go in Action", book.Title)
+	}
+}
+
+func TestGRPCBooksClient_GetBook_NotFound(t *testing.T) {
+	client := newBufconnClient(t, &fakeBooksServer{books: map[int64]*bookspb.Book{}})
+
+	_, err := client.GetBook(context.Background(), 99)
+	if _, ok := err.(*BookNotFoundError); !ok {
+		t.Fatalf("expected *BookNotFoundError, got %T: %v", err, err)
+	}
+}
+
+func TestGRPCBooksClient_GetBook_Inactive(t *testing.T) {
+	srv := &fakeBooksServer{books: map[int64]*bookspb.Book{
+		2: {Id: 2, Title: "Discontinued", Active: false},
+	}}
+	client := newBufconnClient(t, srv)
+
+	_, err := client.GetBook(context.Background(), 2)
+	if _, ok := err.(*BookInactiveError); !ok {
+		t.Fatalf("expected *BookInactiveError, got %T: %v", err, err)
+	}
+}
+
+func TestGRPCBooksClient_GetBooks_BatchMissing(t *testing.T) {
+	srv := &fakeBooksServer{books: map[int64]*bookspb.Book{
+		1: {Id: 1, Title: "A", Price: "1.00", Active: true},
+	}}
+	client := newBufconnClient(t, srv)
+
+	_, err := client.GetBooks(context.Background(), []int64{1, 2})
+	if _, ok := err.(*BookNotFoundError); !ok {
+		t.Fatalf("expected *BookNotFoundError for missing id, got %T: %v", err, err)
+	}
+}
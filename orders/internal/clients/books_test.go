@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *HTTPBooksClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewHTTPBooksClientWithMetrics(srv.URL, 0, logger, &simpleMetrics{})
+}
+
+func TestGetBooks_PrefersBatchRoute(t *testing.T) {
+	var batchCalls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/books:batchGet" {
+			batchCalls.Add(1)
+			json.NewEncoder(w).Encode([]models.Book{
+				{ID: 1, Title: "A", Price: "1.00", Active: true},
+				{ID: 2, Title: "B", Price: "2.00", Active: true},
+			})
+			return
+		}
+		t.Fatalf("unexpected request to %s", r.URL.Path)
+	})
+
+	books, err := client.GetBooks(context.Background(), []int64{1, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(books) != 2 {
+		t.Fatalf("expected 2 books, got %d", len(books))
+	}
+	if batchCalls.Load() != 1 {
+		t.Fatalf("expected exactly 1 batch call, got %d", batchCalls.Load())
+	}
+}
+
+func TestGetBooks_FallsBackWhenBatchRouteMissing(t *testing.T) {
+	var getCalls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/books:batchGet" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		getCalls.Add(1)
+		json.NewEncoder(w).Encode(models.Book{ID: 1, Title: "A", Price: "1.00", Active: true})
+	})
+
+	books, err := client.GetBooks(context.Background(), []int64{1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(books) != 1 {
+		t.Fatalf("expected 1 book, got %d", len(books))
+	}
+	if getCalls.Load() != 1 {
+		t.Fatalf("expected fallback per-ID call, got %d calls", getCalls.Load())
+	}
+
+	// Second call should skip the batch route entirely now that it's cached as unsupported.
+	books, err = client.GetBooks(context.Background(), []int64{1})
+	if err != nil {
+		t.Fatalf("unexpected error on second call: %v", err)
+	}
+	if len(books) != 1 {
+		t.Fatalf("expected 1 book on second call, got %d", len(books))
+	}
+}
+
+func TestGetBooks_DedupesRepeatedIDs(t *testing.T) {
+	var batchRequestIDs []int64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req batchGetRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		batchRequestIDs = req.IDs
+		json.NewEncoder(w).Encode([]models.Book{{ID: 1, Title: "A", Price: "1.00", Active: true}})
+	})
+
+	books, err := client.GetBooks(context.Background(), []int64{1, 1, 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(books) != 1 {
+		t.Fatalf("expected 1 book, got %d", len(books))
+	}
+	if len(batchRequestIDs) != 1 {
+		t.Fatalf("expected deduped request with 1 id, got %v", batchRequestIDs)
+	}
+}
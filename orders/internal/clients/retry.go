@@ -0,0 +1,162 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+)
+
+// RetryConfig controls RetryingBooksClient's backoff schedule.
+type RetryConfig struct {
+	// MaxAttempts caps the total number of calls to the wrapped client
+	// (including the first). 1 disables retries entirely.
+	MaxAttempts int
+	// MaxElapsed bounds the total time spent retrying; once exceeded, the
+	// most recent error is returned even if MaxAttempts hasn't been reached.
+	// 0 disables the bound.
+	MaxElapsed time.Duration
+	// BaseDelay is the delay before the first retry; it doubles on each
+	// subsequent attempt (full jitter applied on top).
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay before jitter is applied.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryConfig matches the defaults config.Config falls back to when
+// the corresponding BOOKS_RETRY_* environment variables are unset.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 3,
+		MaxElapsed:  10 * time.Second,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+	}
+}
+
+// RetryingBooksClient wraps a BooksClient with exponential backoff + full
+// jitter, retrying only errors that are plausibly transient
+// (ServiceUnavailableError, and network/transport errors that don't carry a
+// typed Books error at all). BookNotFoundError and BookInactiveError are
+// permanent answers from the upstream and pass through on the first try.
+type RetryingBooksClient struct {
+	next   BooksClient
+	config RetryConfig
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryingBooksClient wraps next with DefaultRetryConfig's schedule.
+func NewRetryingBooksClient(next BooksClient) *RetryingBooksClient {
+	return NewRetryingBooksClientWithConfig(next, DefaultRetryConfig())
+}
+
+// NewRetryingBooksClientWithConfig is NewRetryingBooksClient with an
+// explicit retry schedule (config.Config.BooksRetry*).
+func NewRetryingBooksClientWithConfig(next BooksClient, cfg RetryConfig) *RetryingBooksClient {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &RetryingBooksClient{next: next, config: cfg, sleep: sleepContext}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *RetryingBooksClient) GetBook(ctx context.Context, bookID int64) (*models.Book, error) {
+	var book *models.Book
+	err := c.withRetry(ctx, func() error {
+		var err error
+		book, err = c.next.GetBook(ctx, bookID)
+		return err
+	})
+	return book, err
+}
+
+func (c *RetryingBooksClient) GetBooks(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
+	var books map[int64]*models.Book
+	err := c.withRetry(ctx, func() error {
+		var err error
+		books, err = c.next.GetBooks(ctx, bookIDs)
+		return err
+	})
+	return books, err
+}
+
+// Ping passes straight through to the wrapped client: it's already a
+// best-effort health probe called on a timer, so retrying it would just
+// delay a health checker's next refresh without buying anything.
+func (c *RetryingBooksClient) Ping(ctx context.Context) error {
+	return c.next.Ping(ctx)
+}
+
+// withRetry runs call, retrying isRetryable errors with exponential backoff
+// + full jitter until MaxAttempts or MaxElapsed is reached.
+func (c *RetryingBooksClient) withRetry(ctx context.Context, call func() error) error {
+	start := time.Now()
+	delay := c.config.BaseDelay
+
+	var err error
+	for attempt := 1; attempt <= c.config.MaxAttempts; attempt++ {
+		err = call()
+		if err == nil || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		if attempt == c.config.MaxAttempts {
+			break
+		}
+		if c.config.MaxElapsed > 0 && time.Since(start) >= c.config.MaxElapsed {
+			break
+		}
+
+		if sleepErr := c.sleep(ctx, fullJitter(delay)); sleepErr != nil {
+			return err
+		}
+		delay *= 2
+		if c.config.MaxDelay > 0 && delay > c.config.MaxDelay {
+			delay = c.config.MaxDelay
+		}
+	}
+	return err
+}
+
+// fullJitter returns a random duration in [0, d), the "full jitter"
+// strategy from AWS's exponential backoff guidance: it spreads out
+// concurrent retries better than a fixed or half-jittered delay.
+func fullJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// isRetryable reports whether err is plausibly transient: a typed
+// ServiceUnavailableError, or an untyped network/transport error (the
+// Books client never got far enough to classify the response). Typed
+// permanent answers - BookNotFoundError, BookInactiveError - and
+// CircuitBreakerError - already managed by the breaker's own cooldown, so
+// retrying it here would just burn attempts against an open circuit - are
+// excluded, as are context cancellation/deadline errors.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch err.(type) {
+	case *BookNotFoundError, *BookInactiveError, *CircuitBreakerError:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,148 @@
+package clients
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+)
+
+// fakeBooksClient lets tests script a sequence of GetBook/GetBooks results
+// without standing up an httptest server.
+type fakeBooksClient struct {
+	calls   atomic.Int32
+	results []error
+	book    *models.Book
+}
+
+func (f *fakeBooksClient) GetBook(ctx context.Context, bookID int64) (*models.Book, error) {
+	n := int(f.calls.Add(1)) - 1
+	if n >= len(f.results) {
+		return f.book, nil
+	}
+	if err := f.results[n]; err != nil {
+		return nil, err
+	}
+	return f.book, nil
+}
+
+func (f *fakeBooksClient) GetBooks(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
+	book, err := f.GetBook(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	return map[int64]*models.Book{0: book}, nil
+}
+
+func (f *fakeBooksClient) Ping(ctx context.Context) error {
+	return nil
+}
+
+func noDelayRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 3,
+		MaxElapsed:  time.Second,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+}
+
+func TestRetryingBooksClient_SucceedsAfterTransientFailures(t *testing.T) {
+	fake := &fakeBooksClient{
+		results: []error{
+			&ServiceUnavailableError{Message: "upstream down"},
+			&ServiceUnavailableError{Message: "upstream down"},
+		},
+		book: &models.Book{ID: 1, Title: "A", Active: true},
+	}
+	client := NewRetryingBooksClientWithConfig(fake, noDelayRetryConfig())
+
+	book, err := client.GetBook(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if book.ID != 1 {
+		t.Fatalf("expected book ID 1, got %d", book.ID)
+	}
+	if fake.calls.Load() != 3 {
+		t.Fatalf("expected 3 calls (2 failures + success), got %d", fake.calls.Load())
+	}
+}
+
+func TestRetryingBooksClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	fake := &fakeBooksClient{
+		results: []error{
+			&ServiceUnavailableError{Message: "down"},
+			&ServiceUnavailableError{Message: "down"},
+			&ServiceUnavailableError{Message: "down"},
+		},
+	}
+	client := NewRetryingBooksClientWithConfig(fake, noDelayRetryConfig())
+
+	_, err := client.GetBook(context.Background(), 1)
+	if _, ok := err.(*ServiceUnavailableError); !ok {
+		t.Fatalf("expected ServiceUnavailableError, got %v (%T)", err, err)
+	}
+	if fake.calls.Load() != 3 {
+		t.Fatalf("expected exactly MaxAttempts=3 calls, got %d", fake.calls.Load())
+	}
+}
+
+func TestRetryingBooksClient_NotFoundPassesThroughImmediately(t *testing.T) {
+	fake := &fakeBooksClient{
+		results: []error{&BookNotFoundError{BookID: 1}},
+	}
+	client := NewRetryingBooksClientWithConfig(fake, noDelayRetryConfig())
+
+	_, err := client.GetBook(context.Background(), 1)
+	if _, ok := err.(*BookNotFoundError); !ok {
+		t.Fatalf("expected BookNotFoundError, got %v (%T)", err, err)
+	}
+	if fake.calls.Load() != 1 {
+		t.Fatalf("expected exactly 1 call (no retry), got %d", fake.calls.Load())
+	}
+}
+
+func TestRetryingBooksClient_InactivePassesThroughImmediately(t *testing.T) {
+	fake := &fakeBooksClient{
+		results: []error{&BookInactiveError{BookID: 1}},
+	}
+	client := NewRetryingBooksClientWithConfig(fake, noDelayRetryConfig())
+
+	_, err := client.GetBook(context.Background(), 1)
+	if _, ok := err.(*BookInactiveError); !ok {
+		t.Fatalf("expected BookInactiveError, got %v (%T)", err, err)
+	}
+	if fake.calls.Load() != 1 {
+		t.Fatalf("expected exactly 1 call (no retry), got %d", fake.calls.Load())
+	}
+}
+
+func TestRetryingBooksClient_StopsOnContextCancellation(t *testing.T) {
+	fake := &fakeBooksClient{
+		results: []error{
+			&ServiceUnavailableError{Message: "down"},
+			&ServiceUnavailableError{Message: "down"},
+			&ServiceUnavailableError{Message: "down"},
+		},
+	}
+	client := NewRetryingBooksClientWithConfig(fake, RetryConfig{
+		MaxAttempts: 5,
+		MaxElapsed:  time.Second,
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    50 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.GetBook(ctx, 1)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if fake.calls.Load() != 1 {
+		t.Fatalf("expected exactly 1 call before bailing on a cancelled context, got %d", fake.calls.Load())
+	}
+}
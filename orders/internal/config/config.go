@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
 )
 
 type Config struct {
@@ -16,6 +19,13 @@ type Config struct {
 	HTTPTimeout      time.Duration
 	CircuitThreshold int
 
+	// BooksTransport selects how the orders service talks to the Books
+	// upstream: "http" (default) or "grpc".
+	BooksTransport string
+
+	// BooksBatchMax caps how many book IDs are sent per batchGet request.
+	BooksBatchMax int // default: 100
+
 	// Feature flags
 	IdempotencyEnabled bool // default: false
 
@@ -26,6 +36,129 @@ type Config struct {
 	// Background DB pool (for cleanup jobs)
 	BackgroundDatabaseURL string // default: DatabaseURL
 	BackgroundMaxConns    int    // default: 2
+
+	// Idempotency key lifecycle
+	IdempotencyTTL           time.Duration // default: 24h
+	IdempotencySweepInterval time.Duration // default: 1h
+
+	// RequestDeadline bounds how long a single handler is allowed to run
+	// (Books fetch + DB write included) before downstream calls are skipped
+	// and the client gets a 499. Default: HTTPTimeout + DBTimeout + 500ms.
+	RequestDeadline time.Duration
+
+	// RedisURL points at the Redis instance backing order lifecycle events
+	// (Pub/Sub fan-out + bounded replay streams). Empty disables event
+	// publishing; OrdersService falls back to events.NoopPublisher.
+	RedisURL string
+
+	// SignatureVerification selects the verification.PayloadVerifier used for
+	// CreateSignedOrder: "hmac" (default) or "eip191" (stub, see
+	// verification.EIP191Verifier).
+	SignatureVerification string
+	// OrderSigningSecret is the shared HMAC secret used when
+	// SignatureVerification == "hmac". Empty disables signed orders.
+	OrderSigningSecret string
+
+	// CursorSigningSecret HMAC-signs opaque pagination cursors so a client
+	// can't forge one pointing at an arbitrary (created_at, id) pair. Empty
+	// (the default) keeps cursors unsigned, matching pre-existing behavior.
+	CursorSigningSecret string
+
+	// BooksRetryMaxAttempts caps how many times clients.RetryingBooksClient
+	// calls the Books upstream (including the first try) before giving up
+	// with the last ServiceUnavailableError. 1 disables retries.
+	BooksRetryMaxAttempts int // default: 3
+	// BooksRetryMaxElapsed bounds the total time spent retrying a single
+	// Books call. 0 disables the bound.
+	BooksRetryMaxElapsed time.Duration // default: 10s
+	// BooksRetryBaseDelay is the delay before the first retry, doubling
+	// (with full jitter) on each subsequent attempt.
+	BooksRetryBaseDelay time.Duration // default: 100ms
+	// BooksRetryMaxDelay caps the backoff delay before jitter is applied.
+	BooksRetryMaxDelay time.Duration // default: 2s
+
+	// IdempotencyStoreBackend selects the idempotency.Store that caches full
+	// CreateOrder responses for replay: "postgres" (default) or "redis"
+	// (requires RedisURL; lets the replay cache scale horizontally without
+	// going through the primary database).
+	IdempotencyStoreBackend string
+
+	// OutboxPollInterval is how often outbox.Dispatcher polls orders_outbox
+	// for unpublished rows when it has nothing to do; a batch that dispatches
+	// at least one row resets to this, and an empty or failing poll backs off
+	// from it toward OutboxMaxBackoff.
+	OutboxPollInterval time.Duration // default: 1s
+	// OutboxBatchSize caps how many rows a single poll claims with
+	// FOR UPDATE SKIP LOCKED.
+	OutboxBatchSize int // default: 50
+	// OutboxMaxBackoff caps the poll interval's exponential backoff.
+	OutboxMaxBackoff time.Duration // default: 30s
+
+	// OTelEnabled turns on the telemetry package's TracerProvider. Off by
+	// default so a deployment without a collector doesn't block on dial.
+	OTelEnabled bool // default: false
+	// OTelExporterEndpoint is the OTLP/gRPC collector address (host:port, no
+	// scheme).
+	OTelExporterEndpoint string // default: localhost:4317
+	// OTelSampleRatio is the fraction of traces sampled, applied via
+	// trace.TraceIDRatioBased.
+	OTelSampleRatio float64 // default: 1.0
+	// OTelServiceName is the resource "service.name" attribute attached to
+	// every span.
+	OTelServiceName string // default: orders
+
+	// HealthCheckInterval is how often the background health checker
+	// refreshes dependency probes (DB, Books) so /readyz and /health read a
+	// cached result instead of dialing out on every request.
+	HealthCheckInterval time.Duration // default: 10s
+
+	// ReconcileInterval is how often the background reconciler re-checks
+	// recently created orders against the Books service for drift (deleted
+	// book, price or title changed since the order snapshotted it).
+	ReconcileInterval time.Duration // default: 1h
+	// ReconcileLookback bounds how far back "recently created" reaches on
+	// each pass, so the job stays cheap regardless of total order volume.
+	ReconcileLookback time.Duration // default: 24h
+
+	// BatchItemInsertEnabled pipelines an order's item INSERTs over a single
+	// SendBatch round trip instead of one round trip per item. Off by
+	// default so existing deployments keep today's per-item behavior until
+	// they opt in.
+	BatchItemInsertEnabled bool // default: false
+
+	// PriceRoundingMode selects the models.RoundingMode line and order
+	// totals round under. Defaults to half-even (banker's rounding), which
+	// matches IEEE 754 and many European tax rules, rather than the
+	// round-half-up this package used before RoundingMode existed.
+	PriceRoundingMode models.RoundingMode // default: half_even
+
+	// FXProviderURL, when set, points CreateOrder's service.HTTPFXProvider at
+	// an external rate service for orders placed in a non-default currency.
+	// Empty disables multi-currency orders: CreateOrder rejects any Currency
+	// other than DefaultCurrency.
+	FXProviderURL string
+	// DefaultCurrency is the ISO 4217 code orders use when the request
+	// doesn't specify one, and what book prices are assumed to be listed in.
+	DefaultCurrency string // default: USD
+
+	// PricingPipelineEnabled turns on the discount/tax/shipping pipeline
+	// (service.WithPricingPipeline). False leaves it nil, which is also what
+	// makes CreateOrder reject any discount_code with a ServiceUnavailableError
+	// instead of silently dropping it.
+	PricingPipelineEnabled bool // default: false
+	// TaxDefaultRate, set as a decimal string (e.g. "0.19" for 19% VAT),
+	// enables a pricing.TaxModifier at that rate for any line whose book
+	// doesn't specify its own tax rate. Empty disables tax.
+	TaxDefaultRate string
+	// ShippingFlatFee, set as a decimal string (e.g. "4.99"), enables a
+	// pricing.ShippingModifier that adds this flat charge to every order.
+	// Empty disables shipping.
+	ShippingFlatFee string
+	// DiscountCodes maps a code a client may pass as
+	// CreateOrderRequest.DiscountCode to the percent off it takes, as a
+	// decimal string (e.g. "SAVE10" -> "0.10"). Parsed from DISCOUNT_CODES, a
+	// comma-separated list of code=percent pairs.
+	DiscountCodes map[string]string
 }
 
 var (
@@ -41,13 +174,35 @@ func Load() (*Config, error) {
 
 func load() (*Config, error) {
 	c := &Config{
-		DBTimeout:            3 * time.Second,
-		HTTPTimeout:          3 * time.Second,
-		CircuitThreshold:     5,
-		IdempotencyEnabled:   false, // Default: disabled for backward compatibility
-		BooksCacheTTL:        5 * time.Second,
-		BooksCacheMaxEntries: 10000,
-		BackgroundMaxConns:   2,
+		DBTimeout:                3 * time.Second,
+		HTTPTimeout:              3 * time.Second,
+		CircuitThreshold:         5,
+		BooksTransport:           "http",
+		BooksBatchMax:            100,
+		IdempotencyEnabled:       false, // Default: disabled for backward compatibility
+		BooksCacheTTL:            5 * time.Second,
+		BooksCacheMaxEntries:     10000,
+		BackgroundMaxConns:       2,
+		IdempotencyTTL:           24 * time.Hour,
+		IdempotencySweepInterval: 1 * time.Hour,
+		SignatureVerification:    "hmac",
+		BooksRetryMaxAttempts:    3,
+		BooksRetryMaxElapsed:     10 * time.Second,
+		BooksRetryBaseDelay:      100 * time.Millisecond,
+		BooksRetryMaxDelay:       2 * time.Second,
+		IdempotencyStoreBackend:  "postgres",
+		OutboxPollInterval:       1 * time.Second,
+		OutboxBatchSize:          50,
+		OutboxMaxBackoff:         30 * time.Second,
+		OTelEnabled:              false,
+		OTelExporterEndpoint:     "localhost:4317",
+		OTelSampleRatio:          1.0,
+		OTelServiceName:          "orders",
+		HealthCheckInterval:      10 * time.Second,
+		ReconcileInterval:        1 * time.Hour,
+		ReconcileLookback:        24 * time.Hour,
+		PriceRoundingMode:        models.RoundHalfEven,
+		DefaultCurrency:          "USD",
 	}
 
 	if v := os.Getenv("DATABASE_URL"); v != "" {
@@ -83,6 +238,19 @@ func load() (*Config, error) {
 		}
 	}
 
+	if v := os.Getenv("BOOKS_TRANSPORT"); v != "" {
+		if v != "http" && v != "grpc" {
+			return nil, fmt.Errorf("BOOKS_TRANSPORT must be 'http' or 'grpc', got %q", v)
+		}
+		c.BooksTransport = v
+	}
+
+	if v := os.Getenv("BOOKS_BATCH_MAX"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			c.BooksBatchMax = n
+		}
+	}
+
 	if v := os.Getenv("ORDERS_ENABLE_IDEMPOTENCY"); v != "" {
 		if b, err := strconv.ParseBool(v); err == nil {
 			c.IdempotencyEnabled = b
@@ -111,5 +279,166 @@ func load() (*Config, error) {
 		}
 	}
 
+	if v := os.Getenv("IDEMPOTENCY_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.IdempotencyTTL = d
+		}
+	}
+	if v := os.Getenv("IDEMPOTENCY_SWEEP_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.IdempotencySweepInterval = d
+		}
+	}
+
+	if v := os.Getenv("REDIS_URL"); v != "" {
+		c.RedisURL = v
+	}
+
+	if v := os.Getenv("SIGNATURE_VERIFICATION"); v != "" {
+		if v != "hmac" && v != "eip191" {
+			return nil, fmt.Errorf("SIGNATURE_VERIFICATION must be 'hmac' or 'eip191', got %q", v)
+		}
+		c.SignatureVerification = v
+	}
+	if v := os.Getenv("ORDER_SIGNING_SECRET"); v != "" {
+		c.OrderSigningSecret = v
+	}
+
+	if v := os.Getenv("CURSOR_SIGNING_SECRET"); v != "" {
+		c.CursorSigningSecret = v
+	}
+
+	if v := os.Getenv("BOOKS_RETRY_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			c.BooksRetryMaxAttempts = n
+		}
+	}
+	if v := os.Getenv("BOOKS_RETRY_MAX_ELAPSED"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.BooksRetryMaxElapsed = d
+		}
+	}
+	if v := os.Getenv("BOOKS_RETRY_BASE_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.BooksRetryBaseDelay = d
+		}
+	}
+	if v := os.Getenv("BOOKS_RETRY_MAX_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.BooksRetryMaxDelay = d
+		}
+	}
+
+	if v := os.Getenv("IDEMPOTENCY_STORE_BACKEND"); v != "" {
+		if v != "postgres" && v != "redis" {
+			return nil, fmt.Errorf("IDEMPOTENCY_STORE_BACKEND must be 'postgres' or 'redis', got %q", v)
+		}
+		c.IdempotencyStoreBackend = v
+	}
+
+	if v := os.Getenv("OUTBOX_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.OutboxPollInterval = d
+		}
+	}
+	if v := os.Getenv("OUTBOX_BATCH_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			c.OutboxBatchSize = n
+		}
+	}
+	if v := os.Getenv("OUTBOX_MAX_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.OutboxMaxBackoff = d
+		}
+	}
+
+	if v := os.Getenv("OTEL_ENABLED"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.OTelEnabled = b
+		}
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		c.OTelExporterEndpoint = v
+	}
+	if v := os.Getenv("OTEL_SAMPLE_RATIO"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			c.OTelSampleRatio = f
+		}
+	}
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		c.OTelServiceName = v
+	}
+
+	if v := os.Getenv("HEALTH_CHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.HealthCheckInterval = d
+		}
+	}
+
+	if v := os.Getenv("RECONCILE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.ReconcileInterval = d
+		}
+	}
+	if v := os.Getenv("RECONCILE_LOOKBACK"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.ReconcileLookback = d
+		}
+	}
+
+	if v := os.Getenv("BATCH_ITEM_INSERT_ENABLED"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.BatchItemInsertEnabled = b
+		}
+	}
+
+	if v := os.Getenv("FX_PROVIDER_URL"); v != "" {
+		c.FXProviderURL = v
+	}
+	if v := os.Getenv("DEFAULT_CURRENCY"); v != "" {
+		c.DefaultCurrency = v
+	}
+
+	if v := os.Getenv("PRICING_PIPELINE_ENABLED"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.PricingPipelineEnabled = b
+		}
+	}
+	if v := os.Getenv("TAX_DEFAULT_RATE"); v != "" {
+		c.TaxDefaultRate = v
+	}
+	if v := os.Getenv("SHIPPING_FLAT_FEE"); v != "" {
+		c.ShippingFlatFee = v
+	}
+	if v := os.Getenv("DISCOUNT_CODES"); v != "" {
+		codes := make(map[string]string)
+		for _, pair := range strings.Split(v, ",") {
+			code, percent, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("DISCOUNT_CODES entry %q must be code=percent", pair)
+			}
+			codes[code] = percent
+		}
+		c.DiscountCodes = codes
+	}
+
+	if v := os.Getenv("PRICE_ROUNDING_MODE"); v != "" {
+		switch models.RoundingMode(v) {
+		case models.RoundHalfUp, models.RoundHalfEven, models.RoundHalfDown, models.RoundDown, models.RoundUp:
+			c.PriceRoundingMode = models.RoundingMode(v)
+		default:
+			return nil, fmt.Errorf("PRICE_ROUNDING_MODE must be one of half_up, half_even, half_down, down, up, got %q", v)
+		}
+	}
+
+	// Computed after HTTP_TIMEOUT/DB_TIMEOUT overrides so it reflects them;
+	// REQUEST_DEADLINE can still override the computed budget directly.
+	c.RequestDeadline = c.HTTPTimeout + c.DBTimeout + 500*time.Millisecond
+	if v := os.Getenv("REQUEST_DEADLINE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.RequestDeadline = d
+		}
+	}
+
 	return c, nil
 }
@@ -0,0 +1,71 @@
+// Package events defines the order lifecycle event envelope and the
+// publisher/stream interfaces that decouple OrdersService from any one
+// transport (Redis Pub/Sub today, potentially Kafka/NATS later).
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Type identifies what kind of order lifecycle transition an Event carries.
+type Type string
+
+const (
+	OrderCreated       Type = "order.created"
+	OrderStatusChanged Type = "order.status_changed"
+	OrderCancelled     Type = "order.cancelled"
+	OrderItemsUpdated  Type = "order.items_updated"
+	// OrderReplayed marks an idempotency-key hit: CreateOrder didn't insert
+	// anything new, it just returned the order an earlier request with the
+	// same key already created.
+	OrderReplayed Type = "order.replayed"
+)
+
+// Event is the envelope published for every order lifecycle transition. Seq
+// is monotonically increasing per OrderID so a reconnecting WebSocket client
+// can pass its last-seen ID back as Last-Event-ID and replay exactly what it
+// missed, without re-delivering or skipping transitions.
+type Event struct {
+	Seq     int64 `json:"seq"`
+	Type    Type  `json:"type"`
+	OrderID int64 `json:"order_id"`
+	// UserID is the owning user, when the order has one (0 otherwise). It lets
+	// a Stream fan an event out to a per-user channel in addition to the
+	// per-order one, without the publisher having to look the order back up.
+	UserID    int64     `json:"user_id,omitempty"`
+	Payload   any       `json:"payload"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventPublisher is implemented by anything OrdersService can hand order
+// lifecycle events to. Implementations must be safe for concurrent use.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Stream is implemented by publishers that also support replay-from and
+// live fan-out, which is what the order events WebSocket endpoint needs.
+// RedisPublisher and InMemoryStream are the two implementations.
+type Stream interface {
+	// Replay returns events recorded for orderID strictly after lastEventID
+	// (a stream-specific cursor; "" means "from the beginning"), oldest first.
+	Replay(ctx context.Context, orderID int64, lastEventID string) ([]Event, error)
+	// Subscribe returns a channel of live events for orderID and an
+	// unsubscribe func the caller must invoke when done. RedisPublisher
+	// closes the channel when ctx is cancelled or the underlying subscription
+	// ends; InMemoryStream ignores ctx and never closes it.
+	Subscribe(ctx context.Context, orderID int64) (<-chan Event, func(), error)
+	// SubscribeAll is Subscribe without an OrderID filter: it fans out every
+	// order's events to one connection. There is no replay counterpart since
+	// there's no single per-connection cursor across every order's stream;
+	// this only ever carries events published from the moment of
+	// subscription, which is what the firehose WebSocket endpoint needs.
+	SubscribeAll(ctx context.Context) (<-chan Event, func(), error)
+}
+
+// NoopPublisher discards every event. It's the default for callers that
+// don't wire up a real EventPublisher (e.g. most existing unit tests).
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(context.Context, Event) error { return nil }
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBufferSize is each subscriber channel's capacity when the caller
+// doesn't pick one via NewInMemoryStreamWithBufferSize.
+const defaultBufferSize = 16
+
+// InMemoryStream is a Stream + EventPublisher backed by in-process channels.
+// It has no persisted history, so Replay always returns nil; it exists for
+// tests and any deployment that doesn't want a Redis dependency for the
+// order events WebSocket endpoints.
+type InMemoryStream struct {
+	mu         sync.Mutex
+	perOrder   map[int64][]chan Event
+	all        []chan Event
+	seq        map[int64]int64
+	bufferSize int
+}
+
+// NewInMemoryStream builds an empty InMemoryStream with the default
+// per-subscriber buffer size.
+func NewInMemoryStream() *InMemoryStream {
+	return NewInMemoryStreamWithBufferSize(defaultBufferSize)
+}
+
+// NewInMemoryStreamWithBufferSize is NewInMemoryStream with an explicit
+// per-subscriber channel capacity, letting a deployment trade off
+// replay-on-lag against memory.
+func NewInMemoryStreamWithBufferSize(bufferSize int) *InMemoryStream {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &InMemoryStream{
+		perOrder:   make(map[int64][]chan Event),
+		seq:        make(map[int64]int64),
+		bufferSize: bufferSize,
+	}
+}
+
+// Publish assigns the next sequence number for event.OrderID and fans it out
+// to every current per-order and firehose subscriber. A subscriber that
+// can't keep up has its oldest buffered event evicted to make room, so a
+// slow client always sees the most recent events rather than getting stuck
+// behind stale ones.
+func (s *InMemoryStream) Publish(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	s.seq[event.OrderID]++
+	event.Seq = s.seq[event.OrderID]
+	perOrder := append([]chan Event(nil), s.perOrder[event.OrderID]...)
+	all := append([]chan Event(nil), s.all...)
+	s.mu.Unlock()
+
+	for _, ch := range perOrder {
+		sendDropOldest(ch, event)
+	}
+	for _, ch := range all {
+		sendDropOldest(ch, event)
+	}
+	return nil
+}
+
+// sendDropOldest sends event on ch, evicting the oldest buffered event first
+// if ch is full, so every subscriber's buffer always holds its most recent
+// events rather than stalling at whatever was oldest.
+func sendDropOldest(ch chan Event, event Event) {
+	for {
+		select {
+		case ch <- event:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// Replay always returns no events: InMemoryStream keeps no history, only
+// live fan-out.
+func (s *InMemoryStream) Replay(ctx context.Context, orderID int64, lastEventID string) ([]Event, error) {
+	return nil, nil
+}
+
+// Subscribe returns a channel of live events for orderID. ctx is not watched
+// and the channel is never closed; delivery stops when unsubscribe is called.
+func (s *InMemoryStream) Subscribe(ctx context.Context, orderID int64) (<-chan Event, func(), error) {
+	ch := make(chan Event, s.bufferSize)
+	s.mu.Lock()
+	s.perOrder[orderID] = append(s.perOrder[orderID], ch)
+	s.mu.Unlock()
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		subs := s.perOrder[orderID]
+		for i, c := range subs {
+			if c == ch {
+				s.perOrder[orderID] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// SubscribeAll returns a channel of every order's live events.
+func (s *InMemoryStream) SubscribeAll(ctx context.Context) (<-chan Event, func(), error) {
+	ch := make(chan Event, s.bufferSize)
+	s.mu.Lock()
+	s.all = append(s.all, ch)
+	s.mu.Unlock()
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, c := range s.all {
+			if c == ch {
+				s.all = append(s.all[:i], s.all[i+1:]...)
+				break
+			}
+		}
+	}
+	return ch, unsubscribe, nil
+}
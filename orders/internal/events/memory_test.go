@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInMemoryStream_SubscribeReceivesOnlyItsOrder(t *testing.T) {
+	stream := NewInMemoryStream()
+	ctx := context.Background()
+
+	ch1, unsub1, err := stream.Subscribe(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unsub1()
+
+	ch2, unsub2, err := stream.Subscribe(ctx, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unsub2()
+
+	if err := stream.Publish(ctx, Event{Type: OrderCreated, OrderID: 1}); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	select {
+	case event := <-ch1:
+		if event.OrderID != 1 {
+			t.Fatalf("expected order 1, got %d", event.OrderID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event on order 1's channel")
+	}
+
+	select {
+	case event := <-ch2:
+		t.Fatalf("order 2's channel should not have received an event for order 1, got %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestInMemoryStream_SubscribeAllReceivesEveryOrder(t *testing.T) {
+	stream := NewInMemoryStream()
+	ctx := context.Background()
+
+	all, unsub, err := stream.SubscribeAll(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unsub()
+
+	if err := stream.Publish(ctx, Event{Type: OrderCreated, OrderID: 1}); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	if err := stream.Publish(ctx, Event{Type: OrderCreated, OrderID: 2}); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	seen := map[int64]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-all:
+			seen[event.OrderID] = true
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+	if !seen[1] || !seen[2] {
+		t.Fatalf("expected to see both order 1 and order 2, got %v", seen)
+	}
+}
+
+func TestInMemoryStream_ReplayAlwaysEmpty(t *testing.T) {
+	stream := NewInMemoryStream()
+	events, err := stream.Replay(context.Background(), 1, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no replayed events, got %d", len(events))
+	}
+}
+
+func TestInMemoryStream_UnsubscribeStopsDelivery(t *testing.T) {
+	stream := NewInMemoryStream()
+	ctx := context.Background()
+
+	ch, unsub, err := stream.Subscribe(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unsub()
+
+	if err := stream.Publish(ctx, Event{Type: OrderCreated, OrderID: 1}); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	select {
+	case event, ok := <-ch:
+		if ok {
+			t.Fatalf("expected no delivery after unsubscribe, got %+v", event)
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// streamMaxLen bounds each per-order replay stream so a never-consumed order
+// can't grow it unboundedly; ~ makes the trim approximate (cheaper) since we
+// only need "enough history for a reconnect", not exact retention.
+const streamMaxLen = 1000
+
+// RedisPublisher publishes order lifecycle events to a per-order Redis
+// stream (for bounded replay) and a matching Pub/Sub channel (for live
+// fan-out to connected WebSocket clients). It implements both EventPublisher
+// and Stream.
+type RedisPublisher struct {
+	client *redis.Client
+}
+
+// NewRedisPublisher builds a RedisPublisher around an existing client.
+func NewRedisPublisher(client *redis.Client) *RedisPublisher {
+	return &RedisPublisher{client: client}
+}
+
+func streamKey(orderID int64) string {
+	return fmt.Sprintf("orders:events:%d", orderID)
+}
+
+func channelKey(orderID int64) string {
+	return fmt.Sprintf("orders:channel:%d", orderID)
+}
+
+// userChannelKey is the Pub/Sub channel an order's owning user's events are
+// additionally published to, so a future per-user subscriber doesn't have to
+// fan in every individual order channel itself.
+func userChannelKey(userID int64) string {
+	return fmt.Sprintf("orders:user:%d", userID)
+}
+
+// channelKeyAll is the Pub/Sub channel every event is additionally published
+// to, backing the firehose WebSocket endpoint (GET /v1/orders/stream).
+const channelKeyAll = "orders:channel:all"
+
+func seqKey(orderID int64) string {
+	return fmt.Sprintf("orders:seq:%d", orderID)
+}
+
+// Publish assigns the next sequence number for event.OrderID, appends the
+// event to its replay stream under that same sequence number as its literal
+// Redis Stream ID, and fans it out over Pub/Sub. Seq on the passed-in Event
+// is overwritten. Using Seq as the stream ID (rather than letting Redis
+// auto-assign one) is what lets Replay translate a client's last-seen Seq
+// straight into an XRANGE cursor.
+func (p *RedisPublisher) Publish(ctx context.Context, event Event) error {
+	seq, err := p.client.Incr(ctx, seqKey(event.OrderID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to allocate event sequence: %w", err)
+	}
+	event.Seq = seq
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey(event.OrderID),
+		ID:     streamEntryID(seq),
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": data},
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to append event to stream: %w", err)
+	}
+
+	if err := p.client.Publish(ctx, channelKey(event.OrderID), data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	if err := p.client.Publish(ctx, channelKeyAll, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event to firehose channel: %w", err)
+	}
+
+	if event.UserID != 0 {
+		if err := p.client.Publish(ctx, userChannelKey(event.UserID), data).Err(); err != nil {
+			return fmt.Errorf("failed to publish event to user channel: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// streamEntryID turns an event's Seq into the literal Redis Stream ID it's
+// stored under. The "-0" sequence part is always zero since seq is already
+// unique and monotonic per order, so it doesn't need Redis's own per-ms
+// disambiguator.
+func streamEntryID(seq int64) string {
+	return fmt.Sprintf("%d-0", seq)
+}
+
+// Replay reads the bounded stream for orderID and returns every event whose
+// Seq is greater than lastEventID (the decimal Seq value of the client's
+// last-seen event, per Event.Seq), oldest first. lastEventID == "" replays
+// the whole retained window.
+func (p *RedisPublisher) Replay(ctx context.Context, orderID int64, lastEventID string) ([]Event, error) {
+	start := "-"
+	if lastEventID != "" {
+		seq, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid last event id %q: %w", lastEventID, err)
+		}
+		start = "(" + streamEntryID(seq)
+	}
+
+	msgs, err := p.client.XRange(ctx, streamKey(orderID), start, "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event stream: %w", err)
+	}
+
+	out := make([]Event, 0, len(msgs))
+	for _, msg := range msgs {
+		raw, ok := msg.Values["data"].(string)
+		if !ok {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			continue
+		}
+		out = append(out, event)
+	}
+	return out, nil
+}
+
+// Subscribe fans out live events for orderID until ctx is cancelled. The
+// caller must call the returned unsubscribe func exactly once.
+func (p *RedisPublisher) Subscribe(ctx context.Context, orderID int64) (<-chan Event, func(), error) {
+	return p.subscribeChannel(ctx, channelKey(orderID), "order channel")
+}
+
+// SubscribeAll fans out every order's live events until ctx is cancelled,
+// backing the firehose WebSocket endpoint.
+func (p *RedisPublisher) SubscribeAll(ctx context.Context) (<-chan Event, func(), error) {
+	return p.subscribeChannel(ctx, channelKeyAll, "firehose channel")
+}
+
+func (p *RedisPublisher) subscribeChannel(ctx context.Context, channel, description string) (<-chan Event, func(), error) {
+	pubsub := p.client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, fmt.Errorf("failed to subscribe to %s: %w", description, err)
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for msg := range pubsub.Channel() {
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, func() { pubsub.Close() }, nil
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/clients"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+)
+
+// DebugHandler exposes internal diagnostics that are useful to operators but
+// not part of the public API contract.
+type DebugHandler struct {
+	booksClient *clients.HTTPBooksClient
+}
+
+func NewDebugHandler(booksClient *clients.HTTPBooksClient) *DebugHandler {
+	return &DebugHandler{booksClient: booksClient}
+}
+
+// Circuit returns the current state of the Books HTTP circuit breaker.
+func (h *DebugHandler) Circuit(c *gin.Context) {
+	if h.booksClient == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "NOT_AVAILABLE", Message: "circuit breaker not available for this transport"})
+		return
+	}
+	c.JSON(http.StatusOK, h.booksClient.CircuitSnapshot())
+}
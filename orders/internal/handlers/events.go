@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/verification"
+)
+
+var eventsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Orders API sits behind our own gateway/proxy for every known caller;
+	// tighten this if the endpoint is ever exposed directly to the browser.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Ping/pong keepalive: without it, a client sitting idle behind a proxy that
+// drops quiet connections (or one that's simply gone without a clean close)
+// looks the same as a healthy subscriber forever.
+const (
+	pongWait   = 60 * time.Second
+	pingPeriod = (pongWait * 9) / 10
+)
+
+// watchForClose pumps incoming frames off conn until it errors or closes,
+// which is required for gorilla/websocket to process pong control frames at
+// all, and closes done so the write loop can stop promptly on a client
+// disconnect instead of waiting for its next write to fail.
+func watchForClose(conn *websocket.Conn, done chan<- struct{}) {
+	defer close(done)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// OrderEventsHandler serves the order lifecycle event WebSocket endpoints.
+type OrderEventsHandler struct {
+	stream   events.Stream
+	logger   *slog.Logger
+	verifier verification.PayloadVerifier
+}
+
+func NewOrderEventsHandler(stream events.Stream, logger *slog.Logger) *OrderEventsHandler {
+	return NewOrderEventsHandlerWithVerifier(stream, logger, nil)
+}
+
+// NewOrderEventsHandlerWithVerifier is NewOrderEventsHandler plus the same
+// verification.PayloadVerifier CreateSignedOrder uses. It gates
+// SubscribeAll: a nil verifier (no signing secret configured, matching
+// NewOrdersServiceWithVerifier's own default) leaves the firehose open,
+// since there's nothing to check a signature against.
+func NewOrderEventsHandlerWithVerifier(stream events.Stream, logger *slog.Logger, verifier verification.PayloadVerifier) *OrderEventsHandler {
+	return &OrderEventsHandler{
+		stream:   stream,
+		logger:   logger,
+		verifier: verifier,
+	}
+}
+
+// Subscribe upgrades to a WebSocket and streams events.Event JSON frames for
+// one order: first any events after the client's Last-Event-ID (header or
+// ?last_event_id= query param), then live events until the connection closes.
+func (h *OrderEventsHandler) Subscribe(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "VALIDATION_ERROR", Message: "invalid order ID"})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	ctx := c.Request.Context()
+
+	conn, err := eventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to upgrade order events connection",
+			slog.Int64("order_id", orderID), slog.String("error", err.Error()))
+		return
+	}
+	defer conn.Close()
+
+	replayed, err := h.stream.Replay(ctx, orderID, lastEventID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to replay order events",
+			slog.Int64("order_id", orderID), slog.String("error", err.Error()))
+	}
+	for _, event := range replayed {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+
+	live, unsubscribe, err := h.stream.Subscribe(ctx, orderID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to subscribe to order events",
+			slog.Int64("order_id", orderID), slog.String("error", err.Error()))
+		return
+	}
+	defer unsubscribe()
+
+	closed := make(chan struct{})
+	go watchForClose(conn, closed)
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case <-ticker.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case event, ok := <-live:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// streamAuthResource is the fixed canonical payload X-Signature is verified
+// against for SubscribeAll, the same way CreateSignedOrder verifies a
+// signature over the request body: there's no per-request body here, so the
+// resource name stands in for one.
+const streamAuthResource = "GET /v1/orders/stream"
+
+// SubscribeAll upgrades to a WebSocket and forwards every order's lifecycle
+// events as they're published, with no replay of history (there's no single
+// cursor across every order's stream to resume from). When a verifier is
+// configured, the client must present X-Signer-ID and an X-Signature
+// computed over streamAuthResource, the same HMAC/EIP-191 scheme
+// CreateSignedOrder uses; without a verifier configured, the endpoint is
+// open.
+func (h *OrderEventsHandler) SubscribeAll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.verifier != nil {
+		signerID := c.GetHeader("X-Signer-ID")
+		signature := c.GetHeader("X-Signature")
+		if signerID == "" || signature == "" {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "SIGNATURE_INVALID", Message: "X-Signer-ID and X-Signature are required"})
+			return
+		}
+		if err := h.verifier.Verify(ctx, signerID, []byte(streamAuthResource), signature); err != nil {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "SIGNATURE_INVALID", Message: err.Error()})
+			return
+		}
+	}
+
+	conn, err := eventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to upgrade order event stream connection", slog.String("error", err.Error()))
+		return
+	}
+	defer conn.Close()
+
+	live, unsubscribe, err := h.stream.SubscribeAll(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to subscribe to order event stream", slog.String("error", err.Error()))
+		return
+	}
+	defer unsubscribe()
+
+	closed := make(chan struct{})
+	go watchForClose(conn, closed)
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case <-ticker.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case event, ok := <-live:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/verification"
+)
+
+func newEventsTestServer(t *testing.T, handler *OrderEventsHandler) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v1/orders/stream", handler.SubscribeAll)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testEventsLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestSubscribeAll_RejectsMissingSignatureWhenVerifierConfigured(t *testing.T) {
+	stream := events.NewInMemoryStream()
+	verifier := verification.NewStaticHMACVerifier("shared-secret")
+	handler := NewOrderEventsHandlerWithVerifier(stream, testEventsLogger(), verifier)
+	srv := newEventsTestServer(t, handler)
+
+	resp, err := http.Get(srv.URL + "/v1/orders/stream")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestSubscribeAll_AllowsValidSignature(t *testing.T) {
+	stream := events.NewInMemoryStream()
+	verifier := verification.NewStaticHMACVerifier("shared-secret")
+	handler := NewOrderEventsHandlerWithVerifier(stream, testEventsLogger(), verifier)
+	srv := newEventsTestServer(t, handler)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/orders/stream"
+	header := http.Header{}
+	header.Set("X-Signer-ID", "signer-1")
+	header.Set("X-Signature", signStreamResource(t, "shared-secret"))
+
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		t.Fatalf("unexpected dial error: %v (status %v)", err, resp)
+	}
+	defer conn.Close()
+
+	if err := stream.Publish(context.Background(), events.Event{Type: events.OrderCreated, OrderID: 1}); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event events.Event
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("unexpected error reading event: %v", err)
+	}
+	if event.OrderID != 1 {
+		t.Fatalf("expected order 1, got %d", event.OrderID)
+	}
+}
+
+func TestSubscribeAll_OpenWhenNoVerifierConfigured(t *testing.T) {
+	stream := events.NewInMemoryStream()
+	handler := NewOrderEventsHandler(stream, testEventsLogger())
+	srv := newEventsTestServer(t, handler)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/orders/stream"
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("unexpected dial error: %v (status %v)", err, resp)
+	}
+	conn.Close()
+}
+
+// signStreamResource mirrors HMACVerifier's own scheme (hex HMAC-SHA256
+// under secret) to produce a valid X-Signature for streamAuthResource; the
+// verifier package doesn't expose a signing helper since it only ever
+// checks signatures produced by the external order-book service.
+func signStreamResource(t *testing.T, secret string) string {
+	t.Helper()
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(streamAuthResource))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,70 +1,72 @@
 package handlers
 
 import (
-	"context"
-	"log/slog"
 	"net/http"
-	"time"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/yourname/bookstore-microservices/orders/internal/clients"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/health"
 )
 
+// HealthHandler serves the three health surfaces the orders API exposes:
+// liveness (/healthz), readiness (/readyz), and verbose dependency detail
+// (/health). All three read health.Registry's cached results rather than
+// probing dependencies on the request path.
 type HealthHandler struct {
-	dbPool      *pgxpool.Pool
-	booksClient clients.BooksClient
-	logger      *slog.Logger
+	registry     *health.Registry
+	shuttingDown atomic.Bool
 }
 
-type HealthResponse struct {
-	Status   string            `json:"status"`
-	Services map[string]string `json:"services"`
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
 }
 
-func NewHealthHandler(dbPool *pgxpool.Pool, booksClient clients.BooksClient, logger *slog.Logger) *HealthHandler {
-	return &HealthHandler{
-		dbPool:      dbPool,
-		booksClient: booksClient,
-		logger:      logger,
-	}
+// MarkShuttingDown flips /healthz to unhealthy, so an orchestrator stops
+// routing new traffic here while the in-flight requests srv.Shutdown is
+// waiting on finish.
+func (h *HealthHandler) MarkShuttingDown() {
+	h.shuttingDown.Store(true)
 }
 
-func (h *HealthHandler) Health(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
-
-	response := HealthResponse{
-		Status:   "healthy",
-		Services: make(map[string]string),
+// Healthz is process-only liveness: it never touches a dependency, so it
+// stays 200 for as long as the process can answer HTTP at all, until
+// MarkShuttingDown flips it.
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
 
-	// Simple database ping
-	if err := h.dbPool.Ping(ctx); err != nil {
-		h.logger.ErrorContext(ctx, "Database health check failed", slog.String("error", err.Error()))
-		response.Status = "unhealthy"
-		response.Services["database"] = "unhealthy"
-	} else {
-		response.Services["database"] = "healthy"
+// Readyz reports whether this instance should receive traffic: 503 if any
+// required dependency's cached check is unhealthy, 200 (with status
+// "degraded") if only an optional one is.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	_, status := h.registry.Snapshot()
+	if status == "unhealthy" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": status})
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
 
-	// Simple books service check by trying to get a non-existent book
-	if _, err := h.booksClient.GetBook(ctx, 99999); err != nil {
-		// We expect this to fail with "not found", but if it's a connection error, mark as unhealthy
-		if err.Error() != "book with ID 99999 not found" {
-			h.logger.WarnContext(ctx, "Books service health check failed", slog.String("error", err.Error()))
-			response.Services["books"] = "unhealthy"
-		} else {
-			response.Services["books"] = "healthy"
-		}
-	} else {
-		response.Services["books"] = "healthy"
-	}
+// HealthResponse is the verbose /health body: overall status plus each
+// dependency's cached check result.
+type HealthResponse struct {
+	Status string                        `json:"status"`
+	Checks map[string]health.CheckResult `json:"checks"`
+}
 
-	if response.Status == "unhealthy" {
-		c.JSON(http.StatusServiceUnavailable, response)
-		return
-	}
+// Health is Readyz plus per-dependency detail (latency, last check time,
+// error), for debugging rather than orchestrator routing decisions.
+func (h *HealthHandler) Health(c *gin.Context) {
+	checks, status := h.registry.Snapshot()
 
-	c.JSON(http.StatusOK, response)
+	code := http.StatusOK
+	if status == "unhealthy" {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, HealthResponse{Status: status, Checks: checks})
 }
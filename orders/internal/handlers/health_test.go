@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/health"
+)
+
+type fakeCheckerResult struct {
+	name   string
+	result health.CheckResult
+}
+
+func (f fakeCheckerResult) Name() string { return f.name }
+
+func (f fakeCheckerResult) Check(ctx context.Context) health.CheckResult { return f.result }
+
+func newHealthTestHandler(t *testing.T, checkers ...fakeCheckerResult) *HealthHandler {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	registry := health.NewRegistry(logger)
+	for _, c := range checkers {
+		registry.Register(c, true)
+	}
+	// Run once synchronously by cancelling right after the initial refresh.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	registry.Run(ctx, time.Hour)
+	return NewHealthHandler(registry)
+}
+
+func TestHealthz_AlwaysOkUntilShuttingDown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newHealthTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	router := gin.New()
+	router.GET("/healthz", handler.Healthz)
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	handler.MarkShuttingDown()
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestReadyz_RequiredDependencyDownReturns503(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newHealthTestHandler(t,
+		fakeCheckerResult{name: "database", result: health.CheckResult{Status: "unhealthy", Error: "connection refused"}},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router := gin.New()
+	router.GET("/readyz", handler.Readyz)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestReadyz_AllDependenciesHealthyReturns200(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newHealthTestHandler(t,
+		fakeCheckerResult{name: "database", result: health.CheckResult{Status: "healthy"}},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router := gin.New()
+	router.GET("/readyz", handler.Readyz)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/idempotency"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdemStore is an in-memory idempotency.Store for handler tests.
+type fakeIdemStore struct {
+	records map[string]idempotency.Record
+}
+
+func newFakeIdemStore() *fakeIdemStore {
+	return &fakeIdemStore{records: make(map[string]idempotency.Record)}
+}
+
+func (s *fakeIdemStore) Check(ctx context.Context, key, requestHash string) (*idempotency.Record, error) {
+	rec, ok := s.records[key]
+	if !ok {
+		return nil, nil
+	}
+	if rec.RequestHash != requestHash {
+		return nil, &idempotency.KeyMismatchError{Key: key, ExistingHash: rec.RequestHash, CreatedAt: rec.CreatedAt}
+	}
+	return &rec, nil
+}
+
+func (s *fakeIdemStore) Put(ctx context.Context, rec idempotency.Record) error {
+	if _, exists := s.records[rec.Key]; !exists {
+		s.records[rec.Key] = rec
+	}
+	return nil
+}
+
+func newTestRouterWithIdemStore(svcImpl *fakeService, store idempotency.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewOrdersHandlerWithIdempotencyStore(svcImpl, slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})), 0, store)
+	v1 := r.Group("/v1")
+	v1.POST("/orders", h.CreateOrder)
+	return r
+}
+
+func TestCreateOrder_IdempotencyStore_ReplaysCachedResponse(t *testing.T) {
+	calls := 0
+	fs := &fakeService{createFn: func(ctx context.Context, req *models.CreateOrderRequest, key string) (*models.Order, error) {
+		calls++
+		return &models.Order{ID: 123, TotalPrice: "19.99"}, nil
+	}}
+	store := newFakeIdemStore()
+	router := newTestRouterWithIdemStore(fs, store)
+	body := `{"items":[{"book_id":1,"quantity":1}]}`
+
+	w1 := httptest.NewRecorder()
+	req1, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(body))
+	req1.Header.Set("Content-Type", "application/json")
+	req1.Header.Set("Idempotency-Key", "replay-key")
+	router.ServeHTTP(w1, req1)
+	require.Equal(t, http.StatusCreated, w1.Code)
+
+	w2 := httptest.NewRecorder()
+	req2, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(body))
+	req2.Header.Set("Content-Type", "application/json")
+	req2.Header.Set("Idempotency-Key", "replay-key")
+	router.ServeHTTP(w2, req2)
+
+	assert.Equal(t, http.StatusCreated, w2.Code)
+	assert.Equal(t, w1.Body.String(), w2.Body.String())
+	assert.Equal(t, w1.Header().Get("Location"), w2.Header().Get("Location"))
+	assert.Equal(t, 1, calls, "service should not be called again on replay")
+}
+
+func TestCreateOrder_IdempotencyStore_HashMismatchRejected(t *testing.T) {
+	fs := &fakeService{createFn: func(ctx context.Context, req *models.CreateOrderRequest, key string) (*models.Order, error) {
+		return &models.Order{ID: 123, TotalPrice: "19.99"}, nil
+	}}
+	store := newFakeIdemStore()
+	router := newTestRouterWithIdemStore(fs, store)
+
+	w1 := httptest.NewRecorder()
+	req1, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(`{"items":[{"book_id":1,"quantity":1}]}`))
+	req1.Header.Set("Content-Type", "application/json")
+	req1.Header.Set("Idempotency-Key", "mismatch-key")
+	router.ServeHTTP(w1, req1)
+	require.Equal(t, http.StatusCreated, w1.Code)
+
+	w2 := httptest.NewRecorder()
+	req2, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(`{"items":[{"book_id":2,"quantity":3}]}`))
+	req2.Header.Set("Content-Type", "application/json")
+	req2.Header.Set("Idempotency-Key", "mismatch-key")
+	router.ServeHTTP(w2, req2)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w2.Code)
+	var er models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w2.Body.Bytes(), &er))
+	assert.Equal(t, "IDEMPOTENCY_KEY_MISMATCH", er.Error)
+}
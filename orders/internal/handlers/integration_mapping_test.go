@@ -31,6 +31,9 @@ func (m *mockOrdersService) CreateOrder(ctx context.Context, req *models.CreateO
 	}
 	return args.Get(0).(*models.Order), args.Error(1)
 }
+func (m *mockOrdersService) CreateSignedOrder(ctx context.Context, req *models.CreateOrderRequest, k, signerID string, rawBody []byte, signature string) (*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
 func (m *mockOrdersService) GetOrderByID(ctx context.Context, id int64) (*models.Order, error) {
 	return nil, errors.New("not implemented")
 }
@@ -40,6 +43,29 @@ func (m *mockOrdersService) ListOrders(ctx context.Context) ([]*models.Order, er
 func (m *mockOrdersService) ListOrdersPaginated(ctx context.Context, p *models.PaginationRequest) (*models.PaginatedResponse[*models.Order], error) {
 	return nil, errors.New("not implemented")
 }
+func (m *mockOrdersService) ListOrdersByCursor(ctx context.Context, cursor string, limit int, backward bool, exactCount bool) (*models.CursorPaginationResponse[*models.Order], error) {
+	return nil, errors.New("not implemented")
+}
+func (m *mockOrdersService) TransitionOrder(ctx context.Context, id int64, action models.OrderAction, reason string) (*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
+func (m *mockOrdersService) UpdateOrderItems(ctx context.Context, id int64, req *models.UpdateOrderItemsRequest) (*models.Order, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+func (m *mockOrdersService) CancelOrder(ctx context.Context, id int64, reason string) (*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
+func (m *mockOrdersService) CancelOrdersForUser(ctx context.Context, userID int64, reason string) ([]*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
+func (m *mockOrdersService) ListOrderEvents(ctx context.Context, orderID int64, pagination *models.PaginationRequest) (*models.PaginatedResponse[*models.OrderEvent], error) {
+	return nil, errors.New("not implemented")
+}
+func (m *mockOrdersService) RoundingMode() models.RoundingMode { return models.RoundHalfUp }
 
 func TestCreateOrder_Mapping_Inactive409(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -102,6 +128,46 @@ func TestCreateOrder_Mapping_ServiceUnavailable503(t *testing.T) {
 	assert.Equal(t, "SERVICE_UNAVAILABLE", er.Error)
 }
 
+func TestUpdateOrderItems_Mapping_VersionConflict409(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockOrdersService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewOrdersHandler(svc, logger)
+	reqBody := `{"items":[{"book_id":1,"quantity":1}],"expected_updated_at":"2026-01-01T00:00:00Z"}`
+	svc.On("UpdateOrderItems", mock.Anything, int64(1), mock.AnythingOfType("*models.UpdateOrderItemsRequest")).
+		Return(nil, &service.OrderVersionConflictError{ID: 1, ExpectedUpdatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.PATCH("/v1/orders/:id", handler.UpdateOrderItems)
+	req := httptest.NewRequest(http.MethodPatch, "/v1/orders/1", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var er models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &er)
+	assert.Equal(t, "ORDER_VERSION_CONFLICT", er.Error)
+}
+
+func TestUpdateOrderItems_Mapping_NotPending422(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockOrdersService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewOrdersHandler(svc, logger)
+	reqBody := `{"items":[{"book_id":1,"quantity":1}],"expected_updated_at":"2026-01-01T00:00:00Z"}`
+	svc.On("UpdateOrderItems", mock.Anything, int64(1), mock.AnythingOfType("*models.UpdateOrderItemsRequest")).
+		Return(nil, &service.OrderNotPendingError{ID: 1, Status: models.StatusShipped})
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.PATCH("/v1/orders/:id", handler.UpdateOrderItems)
+	req := httptest.NewRequest(http.MethodPatch, "/v1/orders/1", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var er models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &er)
+	assert.Equal(t, "ORDER_NOT_PENDING", er.Error)
+}
+
 // Metrics smoke test (unit-level with real middleware not integration server). We simulate by registering route & hitting it.
 func TestMetrics_Smoke(t *testing.T) {
 	gin.SetMode(gin.TestMode)
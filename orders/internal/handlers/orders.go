@@ -1,37 +1,93 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yourname/bookstore-microservices/orders/internal/idempotency"
+	"github.com/yourname/bookstore-microservices/orders/internal/metrics"
 	"github.com/yourname/bookstore-microservices/orders/internal/models"
 	"github.com/yourname/bookstore-microservices/orders/internal/service"
 )
 
+// statusClientClosedRequest is nginx's de facto 499 (no stdlib constant exists
+// because it isn't in the HTTP spec), used when we short-circuit because the
+// client's context was already done.
+const statusClientClosedRequest = 499
+
 type OrdersHandler struct {
 	service service.OrdersService
 	logger  *slog.Logger
+
+	// requestDeadline bounds how long CreateOrder is allowed to spend on the
+	// Books fetch + DB write; 0 disables the bound. See NewOrdersHandlerWithDeadline.
+	requestDeadline time.Duration
+
+	// idemStore caches full CreateOrder responses keyed by Idempotency-Key so a
+	// retry within the TTL window replays the original response instead of
+	// re-running the handler. nil disables replay; the repository's own
+	// (key, order_id, request_hash) guard against duplicate order creation
+	// still applies either way. See NewOrdersHandlerWithIdempotencyStore.
+	idemStore idempotency.Store
 }
 
 func NewOrdersHandler(service service.OrdersService, logger *slog.Logger) *OrdersHandler {
+	return NewOrdersHandlerWithDeadline(service, logger, 0)
+}
+
+// NewOrdersHandlerWithDeadline is NewOrdersHandler plus a per-request deadline
+// (config.Config.RequestDeadline) that bounds the whole handler so it can bail
+// out with a 499 before spending budget on Books or the database.
+func NewOrdersHandlerWithDeadline(service service.OrdersService, logger *slog.Logger, requestDeadline time.Duration) *OrdersHandler {
+	return NewOrdersHandlerWithIdempotencyStore(service, logger, requestDeadline, nil)
+}
+
+// NewOrdersHandlerWithIdempotencyStore is NewOrdersHandlerWithDeadline plus a
+// Store that lets CreateOrder replay a cached response for a repeated
+// Idempotency-Key instead of re-running validation, the Books fetch, and the
+// DB write. A nil store disables replay.
+func NewOrdersHandlerWithIdempotencyStore(service service.OrdersService, logger *slog.Logger, requestDeadline time.Duration, idemStore idempotency.Store) *OrdersHandler {
 	return &OrdersHandler{
-		service: service,
-		logger:  logger,
+		service:         service,
+		logger:          logger,
+		requestDeadline: requestDeadline,
+		idemStore:       idemStore,
 	}
 }
 
 func (h *OrdersHandler) CreateOrder(c *gin.Context) {
 	ctx := c.Request.Context()
+	if h.requestDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.requestDeadline)
+		defer cancel()
+	}
 
 	// Extract idempotency key from header
 	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if idempotencyKey != "" && !isValidIdempotencyKey(idempotencyKey) {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "Idempotency-Key must be 1-255 characters of letters, digits, '-', or '_'")
+		return
+	}
+
+	rawBody, err := c.GetRawData()
+	if err != nil {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "Failed to read request body: "+err.Error())
+		return
+	}
 
 	var req models.CreateOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := json.Unmarshal(rawBody, &req); err != nil {
 		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "Invalid request body: "+err.Error())
 		return
 	}
@@ -41,19 +97,286 @@ func (h *OrdersHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.service.CreateOrder(ctx, &req, idempotencyKey)
+	var requestHash string
+	if h.idemStore != nil && idempotencyKey != "" {
+		sum := sha256.Sum256(rawBody)
+		requestHash = hex.EncodeToString(sum[:])
+
+		rec, err := h.idemStore.Check(ctx, idempotencyKey, requestHash)
+		if err != nil {
+			var mismatch *idempotency.KeyMismatchError
+			if errors.As(err, &mismatch) {
+				h.respondWithError(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_MISMATCH", mismatch.Error())
+				return
+			}
+			h.logger.ErrorContext(ctx, "Idempotency store check failed", slog.String("error", err.Error()))
+		} else if rec != nil {
+			c.Header("Location", fmt.Sprintf("/v1/orders/%d", extractOrderIDFromLocation(rec.ResponseBody)))
+			c.Data(rec.StatusCode, "application/json; charset=utf-8", rec.ResponseBody)
+			return
+		}
+	}
+
+	// Cheap validation is done; don't pay for a Books fetch + DB write if the
+	// client has already gone away.
+	if ctx.Err() != nil {
+		metrics.IncRequestCancelled("validate")
+		h.respondWithError(c, statusClientClosedRequest, "CLIENT_CLOSED_REQUEST", "client closed request")
+		return
+	}
+
+	var order *models.Order
+	if signature := c.GetHeader("X-Signature"); signature != "" {
+		signerID := c.GetHeader("X-Signer-ID")
+		order, err = h.service.CreateSignedOrder(ctx, &req, idempotencyKey, signerID, rawBody, signature)
+	} else {
+		order, err = h.service.CreateOrder(ctx, &req, idempotencyKey)
+	}
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
 	c.Header("Location", fmt.Sprintf("/v1/orders/%d", order.ID))
+	c.Header("X-Rounding-Mode", string(h.service.RoundingMode()))
+	if h.idemStore != nil && idempotencyKey != "" {
+		if body, marshalErr := json.Marshal(order); marshalErr == nil {
+			putErr := h.idemStore.Put(ctx, idempotency.Record{
+				Key:          idempotencyKey,
+				RequestHash:  requestHash,
+				ResponseBody: body,
+				StatusCode:   http.StatusCreated,
+			})
+			if putErr != nil {
+				h.logger.ErrorContext(ctx, "Idempotency store put failed", slog.String("error", putErr.Error()))
+			}
+		}
+	}
 	c.JSON(http.StatusCreated, order)
 }
 
+// extractOrderIDFromLocation pulls the numeric order ID back out of a cached
+// CreateOrder response body so a replay can set the same Location header the
+// original response had.
+func extractOrderIDFromLocation(body []byte) int64 {
+	var partial struct {
+		ID int64 `json:"id"`
+	}
+	_ = json.Unmarshal(body, &partial)
+	return partial.ID
+}
+
+func (h *OrdersHandler) TransitionOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid order ID")
+		return
+	}
+
+	var req models.TransitionOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	order, err := h.service.TransitionOrder(ctx, id, req.Action, req.Reason)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CancelOrder handles DELETE /v1/orders/:id. It goes through the same state
+// machine as TransitionOrder (only pending/confirmed orders can be
+// cancelled) and emits the same order.cancelled event, but reports an
+// illegal attempt as 409 ORDER_NOT_CANCELLABLE rather than INVALID_TRANSITION.
+// The body is optional; an empty or missing reason defaults to
+// "customer_requested".
+func (h *OrdersHandler) CancelOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid order ID")
+		return
+	}
+
+	var req models.CancelOrderRequest
+	// Body is optional, so a missing/empty body is not an error - only
+	// malformed JSON is.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", err.Error())
+			return
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = "customer_requested"
+	}
+
+	order, err := h.service.CancelOrder(ctx, id, req.Reason)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CancelOrdersForUser handles POST /v1/users/:id/orders:cancel: it cancels
+// every one of the user's orders that's still pending or confirmed and
+// reports which ones it actually cancelled. Unlike CancelOrder it never
+// 409s - orders that aren't cancellable are just left out of the response.
+func (h *OrdersHandler) CancelOrdersForUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	idParam := c.Param("id")
+	userID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid user ID")
+		return
+	}
+
+	var req models.CancelOrderRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", err.Error())
+			return
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = "bulk_user_cancel"
+	}
+
+	cancelled, err := h.service.CancelOrdersForUser(ctx, userID, req.Reason)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CancelOrdersForUserResponse{Cancelled: cancelled})
+}
+
+// UpdateOrderItems handles PATCH /v1/orders/:id: it fully replaces a pending
+// order's items, recomputing TotalPrice, and requires expected_updated_at to
+// match the order's current updated_at (optimistic concurrency).
+func (h *OrdersHandler) UpdateOrderItems(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid order ID")
+		return
+	}
+
+	var req models.UpdateOrderItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	order, err := h.service.UpdateOrderItems(ctx, id, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ListOrderEvents handles GET /v1/orders/:id/event-log: the paginated,
+// append-only audit trail for one order (distinct from GET
+// /v1/orders/:id/events, which upgrades to a WebSocket and streams live
+// events.Event frames instead). Sets the same Link/X-Total-Count headers
+// ListOrders's offset-pagination mode does.
+func (h *OrdersHandler) ListOrderEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	idParam := c.Param("id")
+	orderID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid order ID")
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "50")
+	offsetStr := c.DefaultQuery("offset", "0")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "Invalid limit parameter")
+		return
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "Invalid offset parameter")
+		return
+	}
+
+	response, err := h.service.ListOrderEvents(ctx, orderID, &models.PaginationRequest{Limit: limit, Offset: offset})
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	baseURL := fmt.Sprintf("/v1/orders/%d/event-log", orderID)
+
+	var links []string
+	if response.Offset+response.Limit < response.Total {
+		nextOffset := response.Offset + response.Limit
+		links = append(links, fmt.Sprintf("<%s?limit=%d&offset=%d>; rel=\"next\"", baseURL, response.Limit, nextOffset))
+	}
+	if response.Offset > 0 {
+		prevOffset := response.Offset - response.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		links = append(links, fmt.Sprintf("<%s?limit=%d&offset=%d>; rel=\"prev\"", baseURL, response.Limit, prevOffset))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+	c.Header("X-Total-Count", fmt.Sprintf("%d", response.Total))
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *OrdersHandler) ListOrders(c *gin.Context) {
 	ctx := c.Request.Context()
 
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		if _, hasOffset := c.GetQuery("offset"); hasOffset {
+			h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "cursor and offset are mutually exclusive")
+			return
+		}
+		h.listOrdersByCursor(c, ctx, cursor)
+		return
+	}
+
+	// Offset pagination is kept for backward compatibility but is deprecated
+	// in favor of ?cursor=, which doesn't degrade on large tables.
+	c.Header("Deprecation", "true")
+	c.Header("Sunset", "Wed, 31 Dec 2025 23:59:59 GMT")
+
 	// Parse and validate pagination parameters
 	limitStr := c.DefaultQuery("limit", "50")
 	offsetStr := c.DefaultQuery("offset", "0")
@@ -112,6 +435,44 @@ func (h *OrdersHandler) ListOrders(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *OrdersHandler) listOrdersByCursor(c *gin.Context, ctx context.Context, cursor string) {
+	limitStr := c.DefaultQuery("limit", "50")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "Invalid limit parameter")
+		return
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	backward := c.Query("direction") == "prev"
+	exactCount := c.Query("count") == "true"
+
+	response, err := h.service.ListOrdersByCursor(ctx, cursor, limit, backward, exactCount)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	var links []string
+	if response.NextCursor != "" {
+		links = append(links, fmt.Sprintf("</v1/orders?cursor=%s&limit=%d>; rel=\"next\"", response.NextCursor, response.Limit))
+	}
+	if response.PrevCursor != "" {
+		links = append(links, fmt.Sprintf("</v1/orders?cursor=%s&limit=%d&direction=prev>; rel=\"prev\"", response.PrevCursor, response.Limit))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+
+	if response.Total != nil {
+		c.Header("X-Total-Count", fmt.Sprintf("%d", *response.Total))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *OrdersHandler) GetOrder(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -135,6 +496,8 @@ func (h *OrdersHandler) handleServiceError(c *gin.Context, err error) {
 	switch e := err.(type) {
 	case *service.ValidationError:
 		h.respondWithError(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", e.Error())
+	case *service.InvalidCursorError:
+		h.respondWithError(c, http.StatusBadRequest, "INVALID_CURSOR", e.Error())
 	case *service.BookNotFoundError:
 		h.respondWithError(c, http.StatusNotFound, "BOOK_NOT_FOUND", e.Error())
 	case *service.BookNotOrderableError:
@@ -145,6 +508,18 @@ func (h *OrdersHandler) handleServiceError(c *gin.Context, err error) {
 		h.respondWithError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", e.Error())
 	case *service.IdempotencyConflictError:
 		h.respondWithError(c, http.StatusConflict, "IDEMPOTENCY_CONFLICT", e.Error())
+	case *service.ClientCancelledError:
+		h.respondWithError(c, statusClientClosedRequest, "CLIENT_CLOSED_REQUEST", e.Error())
+	case *service.SignatureInvalidError:
+		h.respondWithError(c, http.StatusUnauthorized, "SIGNATURE_INVALID", e.Error())
+	case *service.InvalidTransitionError:
+		h.respondWithError(c, http.StatusConflict, "INVALID_TRANSITION", e.Error())
+	case *service.OrderNotCancellableError:
+		h.respondWithError(c, http.StatusConflict, "ORDER_NOT_CANCELLABLE", e.Error())
+	case *service.OrderVersionConflictError:
+		h.respondWithError(c, http.StatusConflict, "ORDER_VERSION_CONFLICT", e.Error())
+	case *service.OrderNotPendingError:
+		h.respondWithError(c, http.StatusUnprocessableEntity, "ORDER_NOT_PENDING", e.Error())
 	default:
 		h.logger.ErrorContext(c.Request.Context(), "Unhandled service error",
 			slog.String("error", err.Error()),
@@ -160,3 +535,18 @@ func (h *OrdersHandler) respondWithError(c *gin.Context, status int, errorCode,
 	}
 	c.JSON(status, response)
 }
+
+// isValidIdempotencyKey reports whether key is safe to store as a primary key
+// in idempotency_keys: non-empty, bounded in length, and restricted to a
+// charset that can't collide across clients or smuggle control characters.
+func isValidIdempotencyKey(key string) bool {
+	if len(key) == 0 || len(key) > 255 {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/clients"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/service"
+)
+
+// fakeEventsRepo is a minimal, in-memory repository.OrdersRepository good
+// enough to drive CreateOrder end to end. Everything outside that path
+// panics if exercised: this fixture exists to prove events.Stream fan-out,
+// not to cover repository behavior (that's repository package's job).
+type fakeEventsRepo struct {
+	nextID int64
+}
+
+func (r *fakeEventsRepo) CreateOrder(ctx context.Context, order *models.Order) error {
+	r.nextID++
+	order.ID = r.nextID
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+	return nil
+}
+func (r *fakeEventsRepo) CreateOrderWithIdempotency(ctx context.Context, order *models.Order, idempotencyKey, requestHash string) error {
+	return r.CreateOrder(ctx, order)
+}
+func (r *fakeEventsRepo) GetOrderByID(ctx context.Context, id int64) (*models.Order, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) GetOrderByIdempotencyKey(ctx context.Context, idempotencyKey string) (*models.Order, error) {
+	return nil, nil
+}
+func (r *fakeEventsRepo) CheckIdempotencyKey(ctx context.Context, idempotencyKey, requestHash string) (*models.Order, error) {
+	return nil, nil
+}
+func (r *fakeEventsRepo) ListOrders(ctx context.Context) ([]*models.Order, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) ListOrdersPaginated(ctx context.Context, limit, offset int) ([]*models.Order, int, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) ListOrdersByCursor(ctx context.Context, cursor models.Cursor, limit int, backward bool) ([]*models.Order, bool, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) EstimateOrderCount(ctx context.Context) (int64, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) CountOrdersExact(ctx context.Context) (int64, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) ListOrdersCreatedSince(ctx context.Context, since time.Time) ([]*models.Order, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) RecordOrderAnomaly(ctx context.Context, anomaly *models.OrderAnomaly) error {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, olderThan time.Time) (int64, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) TransitionOrder(ctx context.Context, id int64, from, to models.OrderStatus, reason string, now time.Time) (*models.Order, error) {
+	panic("not implemented")
+}
+func (r *fakeEventsRepo) UpdateOrderItems(ctx context.Context, id int64, items []models.OrderItem, adjustments []models.OrderAdjustment, totalPrice, baseTotalPrice string, expectedUpdatedAt, now time.Time) (*models.Order, error) {
+	panic("not implemented")
+}
+
+// fakeEventsBooksClient returns a fixed price for any requested book.
+type fakeEventsBooksClient struct{}
+
+func (fakeEventsBooksClient) GetBook(ctx context.Context, bookID int64) (*models.Book, error) {
+	return &models.Book{ID: bookID, Title: "Test Book", Author: "Test Author", Price: "9.99", Active: true}, nil
+}
+func (fakeEventsBooksClient) GetBooks(ctx context.Context, bookIDs []int64) (map[int64]*models.Book, error) {
+	out := make(map[int64]*models.Book, len(bookIDs))
+	for _, id := range bookIDs {
+		out[id] = &models.Book{ID: id, Title: "Test Book", Author: "Test Author", Price: "9.99", Active: true}
+	}
+	return out, nil
+}
+func (fakeEventsBooksClient) Ping(ctx context.Context) error {
+	return nil
+}
+
+var _ clients.BooksClient = fakeEventsBooksClient{}
+
+// TestCreateOrder_PublishesEventDeliveredToSubscribedWSClient wires a real
+// OrdersService (via NewOrdersServiceWithEvents) to an events.InMemoryStream
+// shared with an OrderEventsHandler, so it proves the full path: CreateOrder
+// commits, publishes events.OrderCreated, and a client already subscribed
+// over the order events WebSocket receives it.
+func TestCreateOrder_PublishesEventDeliveredToSubscribedWSClient(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	stream := events.NewInMemoryStream()
+
+	svc := service.NewOrdersServiceWithEvents(&fakeEventsRepo{}, fakeEventsBooksClient{}, logger, &config.Config{}, stream)
+	ordersHandler := NewOrdersHandler(svc, logger)
+	eventsHandler := NewOrderEventsHandler(stream, logger)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/orders", ordersHandler.CreateOrder)
+	r.GET("/v1/orders/:id/events", eventsHandler.Subscribe)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	// Order 1 is whatever fakeEventsRepo.CreateOrder assigns first.
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/orders/1/events"
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err, "dial status: %v", resp)
+	defer conn.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/orders", bytes.NewBufferString(`{"items":[{"book_id":1,"quantity":1}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event events.Event
+	require.NoError(t, conn.ReadJSON(&event))
+	require.Equal(t, events.OrderCreated, event.Type)
+	require.Equal(t, int64(1), event.OrderID)
+}
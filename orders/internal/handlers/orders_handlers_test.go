@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -21,11 +22,15 @@ type fakeService struct {
 	createFn        func(ctx context.Context, req *models.CreateOrderRequest, key string) (*models.Order, error)
 	getFn           func(ctx context.Context, id int64) (*models.Order, error)
 	listPaginatedFn func(ctx context.Context, p *models.PaginationRequest) (*models.PaginatedResponse[*models.Order], error)
+	listCursorFn    func(ctx context.Context, cursor string, limit int) (*models.CursorPaginationResponse[*models.Order], error)
 }
 
 func (f *fakeService) CreateOrder(ctx context.Context, req *models.CreateOrderRequest, key string) (*models.Order, error) {
 	return f.createFn(ctx, req, key)
 }
+func (f *fakeService) CreateSignedOrder(ctx context.Context, req *models.CreateOrderRequest, key, signerID string, rawBody []byte, signature string) (*models.Order, error) {
+	return f.createFn(ctx, req, key)
+}
 func (f *fakeService) GetOrderByID(ctx context.Context, id int64) (*models.Order, error) {
 	return f.getFn(ctx, id)
 }
@@ -35,6 +40,28 @@ func (f *fakeService) ListOrders(ctx context.Context) ([]*models.Order, error) {
 func (f *fakeService) ListOrdersPaginated(ctx context.Context, p *models.PaginationRequest) (*models.PaginatedResponse[*models.Order], error) {
 	return f.listPaginatedFn(ctx, p)
 }
+func (f *fakeService) ListOrdersByCursor(ctx context.Context, cursor string, limit int, backward bool, exactCount bool) (*models.CursorPaginationResponse[*models.Order], error) {
+	if f.listCursorFn != nil {
+		return f.listCursorFn(ctx, cursor, limit)
+	}
+	return &models.CursorPaginationResponse[*models.Order]{Data: []*models.Order{}, Limit: limit}, nil
+}
+func (f *fakeService) TransitionOrder(ctx context.Context, id int64, action models.OrderAction, reason string) (*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeService) UpdateOrderItems(ctx context.Context, id int64, req *models.UpdateOrderItemsRequest) (*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeService) CancelOrder(ctx context.Context, id int64, reason string) (*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeService) CancelOrdersForUser(ctx context.Context, userID int64, reason string) ([]*models.Order, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeService) ListOrderEvents(ctx context.Context, orderID int64, pagination *models.PaginationRequest) (*models.PaginatedResponse[*models.OrderEvent], error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeService) RoundingMode() models.RoundingMode { return models.RoundHalfUp }
 
 func newTestRouter(svcImpl svc.OrdersService) *gin.Engine {
 	gin.SetMode(gin.TestMode)
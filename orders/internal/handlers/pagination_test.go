@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/service"
 )
 
 // Mock service for testing
@@ -31,6 +32,14 @@ func (m *MockOrdersService) CreateOrder(ctx context.Context, req *models.CreateO
 	return args.Get(0).(*models.Order), args.Error(1)
 }
 
+func (m *MockOrdersService) CreateSignedOrder(ctx context.Context, req *models.CreateOrderRequest, idempotencyKey, signerID string, rawBody []byte, signature string) (*models.Order, error) {
+	args := m.Called(ctx, req, idempotencyKey, signerID, rawBody, signature)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
 func (m *MockOrdersService) GetOrderByID(ctx context.Context, id int64) (*models.Order, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -55,6 +64,56 @@ func (m *MockOrdersService) ListOrdersPaginated(ctx context.Context, pagination
 	return args.Get(0).(*models.PaginatedResponse[*models.Order]), args.Error(1)
 }
 
+func (m *MockOrdersService) ListOrdersByCursor(ctx context.Context, cursor string, limit int, backward bool, exactCount bool) (*models.CursorPaginationResponse[*models.Order], error) {
+	args := m.Called(ctx, cursor, limit, backward, exactCount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CursorPaginationResponse[*models.Order]), args.Error(1)
+}
+
+func (m *MockOrdersService) TransitionOrder(ctx context.Context, id int64, action models.OrderAction, reason string) (*models.Order, error) {
+	args := m.Called(ctx, id, action, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersService) UpdateOrderItems(ctx context.Context, id int64, req *models.UpdateOrderItemsRequest) (*models.Order, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersService) CancelOrder(ctx context.Context, id int64, reason string) (*models.Order, error) {
+	args := m.Called(ctx, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersService) CancelOrdersForUser(ctx context.Context, userID int64, reason string) ([]*models.Order, error) {
+	args := m.Called(ctx, userID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersService) ListOrderEvents(ctx context.Context, orderID int64, pagination *models.PaginationRequest) (*models.PaginatedResponse[*models.OrderEvent], error) {
+	args := m.Called(ctx, orderID, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaginatedResponse[*models.OrderEvent]), args.Error(1)
+}
+
+func (m *MockOrdersService) RoundingMode() models.RoundingMode { return models.RoundHalfUp }
+
 func TestListOrders_Pagination_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -252,6 +311,155 @@ func TestListOrders_Pagination_EmptyResults(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestListOrders_Cursor_FirstPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOrdersService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewOrdersHandler(mockService, logger)
+
+	orders := []*models.Order{
+		{ID: 2, TotalPrice: "19.99", Items: []models.OrderItem{}},
+		{ID: 1, TotalPrice: "29.99", Items: []models.OrderItem{}},
+	}
+	response := &models.CursorPaginationResponse[*models.Order]{
+		Data:       orders,
+		Limit:      50,
+		NextCursor: "opaque-cursor",
+		HasMore:    true,
+	}
+
+	mockService.On("ListOrdersByCursor", mock.Anything, "", 50, false, false).Return(response, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders?cursor=", nil)
+	rec := httptest.NewRecorder()
+
+	router := gin.New()
+	router.GET("/v1/orders", handler.ListOrders)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "", rec.Header().Get("X-Total-Count"))
+	assert.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+	assert.Contains(t, rec.Header().Get("Link"), "cursor=opaque-cursor")
+
+	var result models.CursorPaginationResponse[*models.Order]
+	err := json.Unmarshal(rec.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result.Data))
+	assert.True(t, result.HasMore)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestListOrders_Cursor_RejectsOffsetTogether pins down ListOrders' mutual
+// exclusivity rule: ?cursor= and ?offset= together are ambiguous (offset has
+// no meaning against a keyset position), so the handler rejects the request
+// instead of silently picking one.
+func TestListOrders_Cursor_RejectsOffsetTogether(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOrdersService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewOrdersHandler(mockService, logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders?cursor=&offset=100", nil)
+	rec := httptest.NewRecorder()
+
+	router := gin.New()
+	router.GET("/v1/orders", handler.ListOrders)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	mockService.AssertNotCalled(t, "ListOrdersByCursor", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "ListOrdersPaginated", mock.Anything, mock.Anything)
+}
+
+// TestListOrders_Cursor_MalformedCursorIsRejected covers a cursor that
+// doesn't decode (bad base64url, bad signature, bad JSON payload): the
+// service's InvalidCursorError maps to 400, since the request itself is
+// well-formed and only the opaque token is unusable.
+func TestListOrders_Cursor_MalformedCursorIsRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOrdersService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewOrdersHandler(mockService, logger)
+
+	mockService.On("ListOrdersByCursor", mock.Anything, "not-a-valid-cursor", 50, false, false).
+		Return(nil, &service.InvalidCursorError{Message: "invalid cursor encoding"})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders?cursor=not-a-valid-cursor", nil)
+	rec := httptest.NewRecorder()
+
+	router := gin.New()
+	router.GET("/v1/orders", handler.ListOrders)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListOrders_Cursor_LastPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOrdersService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewOrdersHandler(mockService, logger)
+
+	response := &models.CursorPaginationResponse[*models.Order]{
+		Data:    []*models.Order{},
+		Limit:   50,
+		HasMore: false,
+	}
+
+	mockService.On("ListOrdersByCursor", mock.Anything, "opaque-cursor", 50, false, false).Return(response, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders?cursor=opaque-cursor", nil)
+	rec := httptest.NewRecorder()
+
+	router := gin.New()
+	router.GET("/v1/orders", handler.ListOrders)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Link"))
+
+	mockService.AssertExpectations(t)
+}
+
+// TestListOrders_Cursor_ExplicitCountEmitsTotalHeader covers ?count=true:
+// X-Total-Count should only appear when a caller explicitly opts into the
+// exact count, not on every cursor-paginated page.
+func TestListOrders_Cursor_ExplicitCountEmitsTotalHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOrdersService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewOrdersHandler(mockService, logger)
+
+	total := int64(137)
+	response := &models.CursorPaginationResponse[*models.Order]{
+		Data:  []*models.Order{},
+		Limit: 50,
+		Total: &total,
+	}
+
+	mockService.On("ListOrdersByCursor", mock.Anything, "", 50, false, true).Return(response, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders?cursor=&count=true", nil)
+	rec := httptest.NewRecorder()
+
+	router := gin.New()
+	router.GET("/v1/orders", handler.ListOrders)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "137", rec.Header().Get("X-Total-Count"))
+
+	mockService.AssertExpectations(t)
+}
+
 func TestCreateOrder_LocationHeader(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
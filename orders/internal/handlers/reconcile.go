@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/service"
+)
+
+// ReconcileHandler exposes an on-demand trigger for the order reconciler, for
+// operators who don't want to wait for its next scheduled pass.
+type ReconcileHandler struct {
+	reconciler *service.Reconciler
+}
+
+func NewReconcileHandler(reconciler *service.Reconciler) *ReconcileHandler {
+	return &ReconcileHandler{reconciler: reconciler}
+}
+
+// Reconcile runs a reconciliation pass over orders created at or after
+// ?since= (RFC3339; defaults to 24h ago) and reports how many anomalies it
+// found.
+func (h *ReconcileHandler) Reconcile(c *gin.Context) {
+	since := time.Now().Add(-24 * time.Hour)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{Error: "VALIDATION_ERROR", Message: "since must be RFC3339"})
+			return
+		}
+		since = parsed
+	}
+
+	anomalies, err := h.reconciler.ReconcileSince(c.Request.Context(), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "INTERNAL_ERROR", Message: "reconciliation failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"since": since, "anomalies_found": anomalies})
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBPinger is the subset of *pgxpool.Pool DBChecker needs.
+type DBPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// DBChecker probes the primary database pool.
+type DBChecker struct {
+	pool DBPinger
+}
+
+func NewDBChecker(pool *pgxpool.Pool) *DBChecker {
+	return &DBChecker{pool: pool}
+}
+
+func (c *DBChecker) Name() string { return "database" }
+
+func (c *DBChecker) Check(ctx context.Context) CheckResult {
+	if err := c.pool.Ping(ctx); err != nil {
+		return CheckResult{Status: "unhealthy", Error: err.Error()}
+	}
+	return CheckResult{Status: "healthy"}
+}
+
+// BooksPinger is the subset of clients.BooksClient BooksChecker needs.
+type BooksPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// BooksChecker probes the Books service via its dedicated Ping method
+// rather than GetBook, so background probing doesn't touch the circuit
+// breaker guarding real traffic.
+type BooksChecker struct {
+	client BooksPinger
+}
+
+func NewBooksChecker(client BooksPinger) *BooksChecker {
+	return &BooksChecker{client: client}
+}
+
+func (c *BooksChecker) Name() string { return "books" }
+
+func (c *BooksChecker) Check(ctx context.Context) CheckResult {
+	if err := c.client.Ping(ctx); err != nil {
+		return CheckResult{Status: "unhealthy", Error: err.Error()}
+	}
+	return CheckResult{Status: "healthy"}
+}
@@ -0,0 +1,119 @@
+// Package health runs periodic dependency probes in the background so
+// request-path health endpoints read a cached result instead of dialing
+// the database or Books on every call.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// CheckResult is one checker's most recent probe outcome.
+type CheckResult struct {
+	Status      string    `json:"status"` // "healthy" or "unhealthy"
+	LatencyMs   int64     `json:"latency_ms"`
+	LastChecked time.Time `json:"last_checked"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Checker probes one dependency. Check should do the actual probe and
+// return its Status/Error only - Registry fills in LatencyMs and
+// LastChecked around the call.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) CheckResult
+}
+
+// entry pairs a Checker with whether its failure fails readiness outright
+// (required) or just degrades the overall status while staying available
+// (optional).
+type entry struct {
+	checker  Checker
+	required bool
+}
+
+// Registry runs registered Checkers on a timer and serves their latest
+// results without blocking on a live probe, so /readyz and /health never
+// pay a dependency's latency (or outage) on the request path.
+type Registry struct {
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	entries []entry
+	results map[string]CheckResult
+}
+
+func NewRegistry(logger *slog.Logger) *Registry {
+	return &Registry{logger: logger, results: make(map[string]CheckResult)}
+}
+
+// Register adds checker to the set Run refreshes. required determines
+// whether its failure fails readiness outright (required) or only degrades
+// the overall status (optional). Not safe to call concurrently with Run.
+func (r *Registry) Register(checker Checker, required bool) {
+	r.entries = append(r.entries, entry{checker: checker, required: required})
+}
+
+// Run probes every registered checker immediately, then again every
+// interval, until ctx is cancelled. Call it in its own goroutine.
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	r.refresh(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.refresh(ctx)
+		}
+	}
+}
+
+func (r *Registry) refresh(ctx context.Context) {
+	for _, e := range r.entries {
+		start := time.Now()
+		result := e.checker.Check(ctx)
+		result.LatencyMs = time.Since(start).Milliseconds()
+		result.LastChecked = time.Now()
+
+		if result.Status != "healthy" {
+			r.logger.WarnContext(ctx, "Dependency health check failed",
+				slog.String("dependency", e.checker.Name()),
+				slog.Bool("required", e.required),
+				slog.String("error", result.Error))
+		}
+
+		r.mu.Lock()
+		r.results[e.checker.Name()] = result
+		r.mu.Unlock()
+	}
+}
+
+// Snapshot returns the most recent result per checker alongside the overall
+// status: "unhealthy" if any required checker's last result isn't healthy,
+// "degraded" if only optional checkers are unhealthy, "healthy" otherwise. A
+// checker that hasn't run yet reports as its zero CheckResult, which counts
+// as unhealthy.
+func (r *Registry) Snapshot() (map[string]CheckResult, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make(map[string]CheckResult, len(r.entries))
+	status := "healthy"
+	for _, e := range r.entries {
+		result := r.results[e.checker.Name()]
+		results[e.checker.Name()] = result
+		if result.Status != "healthy" {
+			if e.required {
+				status = "unhealthy"
+			} else if status != "unhealthy" {
+				status = "degraded"
+			}
+		}
+	}
+	return results, status
+}
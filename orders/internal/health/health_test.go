@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChecker returns whatever status is set at call time and counts how
+// many times Check ran, so tests can tell a cached read from a refreshed
+// one.
+type fakeChecker struct {
+	name   string
+	status atomic.Value // string
+	calls  atomic.Int32
+}
+
+func newFakeChecker(name, status string) *fakeChecker {
+	c := &fakeChecker{name: name}
+	c.status.Store(status)
+	return c
+}
+
+func (c *fakeChecker) Name() string { return c.name }
+
+func (c *fakeChecker) Check(ctx context.Context) CheckResult {
+	c.calls.Add(1)
+	return CheckResult{Status: c.status.Load().(string)}
+}
+
+func (c *fakeChecker) setStatus(status string) {
+	c.status.Store(status)
+}
+
+func newTestRegistry() *Registry {
+	return NewRegistry(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+}
+
+// TestRegistry_SnapshotServesCachedResultBetweenRefreshes asserts Snapshot
+// itself never probes: two Snapshot calls after a single refresh both see
+// the same one Check call.
+func TestRegistry_SnapshotServesCachedResultBetweenRefreshes(t *testing.T) {
+	checker := newFakeChecker("db", "healthy")
+	r := newTestRegistry()
+	r.Register(checker, true)
+
+	r.refresh(context.Background())
+
+	results, status := r.Snapshot()
+	assert.Equal(t, "healthy", status)
+	assert.Equal(t, "healthy", results["db"].Status)
+
+	_, status = r.Snapshot()
+	assert.Equal(t, "healthy", status)
+	assert.Equal(t, int32(1), checker.calls.Load(), "Snapshot must read the cached result, not re-probe")
+}
+
+// TestRegistry_RequiredCheckerFailureFlipsReadyToUnhealthy covers the core
+// readiness rule: once a required checker's refreshed result is unhealthy,
+// the overall status flips from healthy to unhealthy.
+func TestRegistry_RequiredCheckerFailureFlipsReadyToUnhealthy(t *testing.T) {
+	checker := newFakeChecker("db", "healthy")
+	r := newTestRegistry()
+	r.Register(checker, true)
+
+	r.refresh(context.Background())
+	_, status := r.Snapshot()
+	assert.Equal(t, "healthy", status)
+
+	checker.setStatus("unhealthy")
+	r.refresh(context.Background())
+
+	_, status = r.Snapshot()
+	assert.Equal(t, "unhealthy", status)
+}
+
+// TestRegistry_OptionalCheckerFailureDegradesWithout503 covers an optional
+// checker failing: the overall status degrades but readiness isn't refused
+// outright the way a required failure is.
+func TestRegistry_OptionalCheckerFailureDegradesWithout503(t *testing.T) {
+	required := newFakeChecker("db", "healthy")
+	optional := newFakeChecker("books", "healthy")
+	r := newTestRegistry()
+	r.Register(required, true)
+	r.Register(optional, false)
+
+	r.refresh(context.Background())
+	_, status := r.Snapshot()
+	assert.Equal(t, "healthy", status)
+
+	optional.setStatus("unhealthy")
+	r.refresh(context.Background())
+
+	_, status = r.Snapshot()
+	assert.Equal(t, "degraded", status, "an optional checker failing should degrade, not fail, readiness")
+}
@@ -0,0 +1,62 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore is the default Store backend: one row per key in
+// idempotency_responses, a table kept deliberately separate from
+// idempotency_keys so this cache can be dropped/rebuilt without touching the
+// transactional bookkeeping CreateOrderWithIdempotency relies on.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+	ttl  time.Duration
+}
+
+// NewPostgresStore builds a PostgresStore whose records expire ttl after
+// they're written.
+func NewPostgresStore(pool *pgxpool.Pool, ttl time.Duration) *PostgresStore {
+	return &PostgresStore{pool: pool, ttl: ttl}
+}
+
+func (s *PostgresStore) Check(ctx context.Context, key, requestHash string) (*Record, error) {
+	var rec Record
+	query := `
+		SELECT key, request_hash, response_body, status_code, created_at, expires_at
+		FROM idempotency_responses
+		WHERE key = $1 AND expires_at > NOW()
+	`
+	err := s.pool.QueryRow(ctx, query, key).Scan(
+		&rec.Key, &rec.RequestHash, &rec.ResponseBody, &rec.StatusCode, &rec.CreatedAt, &rec.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check idempotency store: %w", err)
+	}
+	if rec.RequestHash != requestHash {
+		return nil, &KeyMismatchError{Key: key, ExistingHash: rec.RequestHash, CreatedAt: rec.CreatedAt}
+	}
+	return &rec, nil
+}
+
+func (s *PostgresStore) Put(ctx context.Context, rec Record) error {
+	if rec.ExpiresAt.IsZero() {
+		rec.ExpiresAt = time.Now().Add(s.ttl)
+	}
+	query := `
+		INSERT INTO idempotency_responses (key, request_hash, response_body, status_code, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, NOW(), $5)
+		ON CONFLICT (key) DO NOTHING
+	`
+	if _, err := s.pool.Exec(ctx, query, rec.Key, rec.RequestHash, rec.ResponseBody, rec.StatusCode, rec.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to store idempotency response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is the horizontally-scalable Store backend: one JSON-encoded
+// value per key with Redis's own expiry enforcing the TTL, so unlike
+// PostgresStore it needs no separate sweep job.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisStore builds a RedisStore whose records expire ttl after they're
+// written.
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+func redisRecordKey(key string) string {
+	return "idempotency:" + key
+}
+
+func (s *RedisStore) Check(ctx context.Context, key, requestHash string) (*Record, error) {
+	data, err := s.client.Get(ctx, redisRecordKey(key)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check idempotency store: %w", err)
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency record: %w", err)
+	}
+	if rec.RequestHash != requestHash {
+		return nil, &KeyMismatchError{Key: key, ExistingHash: rec.RequestHash, CreatedAt: rec.CreatedAt}
+	}
+	return &rec, nil
+}
+
+func (s *RedisStore) Put(ctx context.Context, rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+	// SetNX: the first writer for a key wins, consistent with
+	// PostgresStore's ON CONFLICT DO NOTHING.
+	if err := s.client.SetNX(ctx, redisRecordKey(rec.Key), data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotency response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Record is the cached outcome of a request made under an idempotency key:
+// the exact response a replay should return, so a retry gets back
+// byte-identical bytes instead of just a pointer at the same underlying
+// order.
+type Record struct {
+	Key          string
+	RequestHash  string
+	ResponseBody []byte
+	StatusCode   int
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// KeyMismatchError means Key was already used to store a Record whose
+// RequestHash doesn't match the hash of the request presenting it now - the
+// client reused a key after changing the request body.
+type KeyMismatchError struct {
+	Key          string
+	ExistingHash string
+	CreatedAt    time.Time
+}
+
+func (e *KeyMismatchError) Error() string {
+	return fmt.Sprintf("idempotency key '%s' already used with different request body (original request at %s)", e.Key, e.CreatedAt.Format(time.RFC3339))
+}
+
+// Store persists idempotency Records behind a pluggable backend -
+// PostgresStore (default) or RedisStore, selected by
+// config.IdempotencyStoreBackend - so a CreateOrder retry within the TTL
+// window gets back the exact response the first attempt produced.
+//
+// Store is deliberately a cache layer on top of, not a replacement for, the
+// transactional (key, order_id, request_hash) bookkeeping
+// OrdersRepository.CreateOrderWithIdempotency already does inside the order
+// INSERT's own transaction: that's what stops two concurrent requests
+// sharing a key from creating two orders. A Store whose backend isn't the
+// primary database (Redis) can't join that transaction, so Store only ever
+// governs whether a response gets replayed, never whether an order gets
+// created.
+type Store interface {
+	// Check looks up key. A nil Record and nil error mean key hasn't been
+	// seen before - the caller should proceed and call Put once it has a
+	// response. *KeyMismatchError means key was seen before with a different
+	// requestHash. Otherwise the Record is the response to replay verbatim.
+	Check(ctx context.Context, key, requestHash string) (*Record, error)
+	// Put stores rec so a future Check with the same key replays it.
+	Put(ctx context.Context, rec Record) error
+}
@@ -0,0 +1,64 @@
+// Package idempotency runs the background job that enforces the lifecycle of
+// idempotency_keys rows: CreateOrderWithIdempotency inserts them, and Sweeper
+// deletes ones past their TTL so the table doesn't grow unbounded.
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SweepStore is the subset of the orders repository the sweeper needs. It is
+// satisfied by repository.OrdersRepository. Not to be confused with Store,
+// which caches full responses for replay rather than tracking the
+// (key, order_id, request_hash) rows this sweeps.
+type SweepStore interface {
+	DeleteExpiredIdempotencyKeys(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// Sweeper periodically deletes idempotency keys older than TTL.
+type Sweeper struct {
+	store    SweepStore
+	ttl      time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewSweeper builds a Sweeper that deletes keys older than ttl every interval.
+func NewSweeper(store SweepStore, ttl, interval time.Duration, logger *slog.Logger) *Sweeper {
+	return &Sweeper{
+		store:    store,
+		ttl:      ttl,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run blocks, sweeping expired keys every interval until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.InfoContext(ctx, "Idempotency sweeper stopping")
+			return
+		case <-ticker.C:
+			s.sweepOnce(ctx)
+		}
+	}
+}
+
+func (s *Sweeper) sweepOnce(ctx context.Context) {
+	cutoff := time.Now().Add(-s.ttl)
+	deleted, err := s.store.DeleteExpiredIdempotencyKeys(ctx, cutoff)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Idempotency sweep failed", slog.String("error", err.Error()))
+		return
+	}
+	if deleted > 0 {
+		s.logger.InfoContext(ctx, "Swept expired idempotency keys", slog.Int64("deleted", deleted))
+	}
+}
@@ -23,10 +23,123 @@ var (
 	)
 
 	BooksCircuitOpens = promauto.With(registry).NewCounter(prometheus.CounterOpts{Namespace: "orders", Name: "books_circuit_open_total", Help: "Times the Books HTTP circuit opened"})
+
+	booksRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: "orders", Name: "books_requests_total", Help: "Books client requests by result."},
+		[]string{"result"},
+	)
+	booksLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{Namespace: "orders", Name: "books_request_duration_seconds", Help: "Books client latency in seconds.", Buckets: prometheus.DefBuckets},
+	)
+	circuitState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Namespace: "orders", Name: "books_circuit_state", Help: "1 if the Books circuit breaker is currently in the given state, 0 otherwise."},
+		[]string{"state"},
+	)
+	circuitTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: "orders", Name: "books_circuit_transitions_total", Help: "Books circuit breaker state transitions."},
+		[]string{"from", "to"},
+	)
+	booksBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{Namespace: "orders", Name: "books_batch_size", Help: "Number of book IDs sent per batchGet request.", Buckets: []float64{1, 2, 5, 10, 25, 50, 100, 250}},
+	)
+
+	requestsCancelled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: "orders", Name: "requests_cancelled_total", Help: "Requests short-circuited because the client's context was already done before a given stage."},
+		[]string{"stage"},
+	)
+
+	orderCancellations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: "orders", Name: "cancellations_total", Help: "Orders successfully cancelled, by reason."},
+		[]string{"reason"},
+	)
+
+	outboxFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{Namespace: "orders", Name: "outbox_failures_total", Help: "Outbox rows whose Publisher.Publish call failed and were left for retry on the next poll."},
+	)
+	outboxLag = prometheus.NewHistogram(
+		prometheus.HistogramOpts{Namespace: "orders", Name: "outbox_lag_seconds", Help: "Time between an outbox row being created and the dispatcher claiming it.", Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300}},
+	)
+
+	reconcileAnomalies = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: "orders", Name: "reconcile_anomalies_total", Help: "Drift the order reconciler found between a snapshotted order item and the Books service, by anomaly type."},
+		[]string{"type"},
+	)
 )
 
 func init() {
-	registry.MustRegister(httpRequests, httpLatency)
+	registry.MustRegister(httpRequests, httpLatency, booksRequests, booksLatency, circuitState, circuitTransitions, booksBatchSize, requestsCancelled, orderCancellations, outboxFailures, outboxLag, reconcileAnomalies)
+}
+
+// BooksMetrics adapts the package's Prometheus collectors to the
+// clients.BooksMetrics interface so HTTPBooksClient can report real metrics.
+type BooksMetrics struct{}
+
+func NewBooksMetrics() *BooksMetrics { return &BooksMetrics{} }
+
+func (*BooksMetrics) IncBooksRequest(result string) {
+	booksRequests.WithLabelValues(result).Inc()
+}
+
+func (*BooksMetrics) ObserveBooksLatency(duration time.Duration) {
+	booksLatency.Observe(duration.Seconds())
+}
+
+// SetCircuitState flips the gauge for the new state to 1 and every other
+// known state to 0, so a single `books_circuit_state == 1` query always
+// identifies the current state.
+func (*BooksMetrics) SetCircuitState(state string) {
+	for _, s := range []string{"closed", "open", "half_open"} {
+		if s == state {
+			circuitState.WithLabelValues(s).Set(1)
+		} else {
+			circuitState.WithLabelValues(s).Set(0)
+		}
+	}
+}
+
+func (*BooksMetrics) IncCircuitTransition(from, to string) {
+	circuitTransitions.WithLabelValues(from, to).Inc()
+	if to == "open" {
+		BooksCircuitOpens.Inc()
+	}
+}
+
+func (*BooksMetrics) ObserveBooksBatchSize(size int) {
+	booksBatchSize.Observe(float64(size))
+}
+
+// IncRequestCancelled records that a request was short-circuited at stage
+// because the deadline-bound context was already done (client disconnected
+// or the request budget was exhausted) before that stage started.
+func IncRequestCancelled(stage string) {
+	requestsCancelled.WithLabelValues(stage).Inc()
+}
+
+// IncOrderCancellation records a successful order cancellation, labeled with
+// its reason string (e.g. "customer_requested", "bulk_user_cancel").
+func IncOrderCancellation(reason string) {
+	orderCancellations.WithLabelValues(reason).Inc()
+}
+
+// IncOutboxFailure records an outbox row whose Publisher.Publish call
+// failed; the row stays unpublished and is retried on a later poll, so a
+// sustained rise here means a downstream consumer has been unreachable for
+// a while rather than one-off delivery failures.
+func IncOutboxFailure() {
+	outboxFailures.Inc()
+}
+
+// ObserveOutboxLag records how long a row sat in orders_outbox before the
+// dispatcher claimed it, which is the backlog age a downstream consumer
+// would otherwise have no visibility into.
+func ObserveOutboxLag(lag time.Duration) {
+	outboxLag.Observe(lag.Seconds())
+}
+
+// IncReconcileAnomaly records one instance of reconciler-detected drift,
+// labeled by anomaly type (e.g. "price_drift", "book_deleted").
+func IncReconcileAnomaly(anomalyType string) {
+	reconcileAnomalies.WithLabelValues(anomalyType).Inc()
 }
 
 // Handler exposes /metrics endpoint
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"pgregory.net/rapid"
+)
+
+// genPrice draws a price in [0.01, 9999.99] with up to 4 decimal places -
+// the range real book prices and order totals fall in, wide enough that
+// shrinking still finds the smallest failing case rather than one clamped
+// to a boundary.
+func genPrice(t *rapid.T) decimal.Decimal {
+	cents := rapid.IntRange(100, 99999900).Draw(t, "price_ten_thousandths")
+	return decimal.NewFromInt(int64(cents)).Shift(-4)
+}
+
+// genQuantity draws an order line quantity in [1, 1000].
+func genQuantity(t *rapid.T) int64 {
+	return int64(rapid.IntRange(1, 1000).Draw(t, "quantity"))
+}
+
+// orderTotal is the same computation createOrder does: round each line to
+// 2dp individually, then sum the rounded lines - never round the grand
+// total at the end.
+func orderTotal(prices []decimal.Decimal, quantities []int64) decimal.Decimal {
+	total := decimal.Zero
+	for i, price := range prices {
+		lineTotal := price.Mul(decimal.NewFromInt(quantities[i])).Round(2)
+		total = total.Add(lineTotal)
+	}
+	return total
+}
+
+// TestFormatPrice_RoundTripIsIdempotent covers FormatPrice(ParsePrice(FormatPrice(d)))
+// == FormatPrice(d) for any price in range: once a price has been formatted
+// to its canonical 2dp string, re-parsing and re-formatting it must be a
+// no-op.
+func TestFormatPrice_RoundTripIsIdempotent(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		d := genPrice(t)
+
+		once := FormatPrice(d)
+		parsed, err := ParsePrice(once)
+		if err != nil {
+			t.Fatalf("ParsePrice(%q) failed: %v", once, err)
+		}
+		twice := FormatPrice(parsed)
+
+		if once != twice {
+			t.Fatalf("round-trip drifted: FormatPrice(d)=%q, FormatPrice(ParsePrice(FormatPrice(d)))=%q", once, twice)
+		}
+	})
+}
+
+// TestOrderTotal_MatchesCanonicalSum covers sum_i(round2(p_i*q_i)) against an
+// independently-computed decimal.Sum of the same rounded line totals, so the
+// order in which lines are accumulated can't introduce drift.
+func TestOrderTotal_MatchesCanonicalSum(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 20).Draw(t, "line_count")
+		prices := make([]decimal.Decimal, n)
+		quantities := make([]int64, n)
+		lineTotals := make([]decimal.Decimal, n)
+		for i := 0; i < n; i++ {
+			prices[i] = genPrice(t)
+			quantities[i] = genQuantity(t)
+			lineTotals[i] = prices[i].Mul(decimal.NewFromInt(quantities[i])).Round(2)
+		}
+
+		got := orderTotal(prices, quantities)
+		want := decimal.Sum(decimal.Zero, lineTotals...)
+
+		if !got.Equal(want) {
+			t.Fatalf("orderTotal=%s, decimal.Sum of the same rounded lines=%s", got, want)
+		}
+	})
+}
+
+// TestOrderTotal_IsPermutationInvariant covers summing a shuffled order of
+// the same lines: addition of already-rounded line totals is commutative
+// and associative regardless of rounding mode, so reordering lines must
+// never change the total.
+func TestOrderTotal_IsPermutationInvariant(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 20).Draw(t, "line_count")
+		orderLines := make([]priceQtyLine, n)
+		for i := 0; i < n; i++ {
+			orderLines[i] = priceQtyLine{price: genPrice(t), quantity: genQuantity(t)}
+		}
+
+		shuffled := rapid.Permutation(orderLines).Draw(t, "shuffled_lines")
+
+		original := orderTotal(linePrices(orderLines), lineQuantities(orderLines))
+		reordered := orderTotal(linePrices(shuffled), lineQuantities(shuffled))
+
+		if !original.Equal(reordered) {
+			t.Fatalf("permuting lines changed the total: original=%s, shuffled=%s", original, reordered)
+		}
+	})
+}
+
+// priceQtyLine is one price+quantity pair fed into orderTotal. Pairing them
+// as a single value lets rapid.Permutation shuffle both fields together.
+type priceQtyLine struct {
+	price    decimal.Decimal
+	quantity int64
+}
+
+func linePrices(lines []priceQtyLine) []decimal.Decimal {
+	out := make([]decimal.Decimal, len(lines))
+	for i, l := range lines {
+		out[i] = l.price
+	}
+	return out
+}
+
+func lineQuantities(lines []priceQtyLine) []int64 {
+	out := make([]int64, len(lines))
+	for i, l := range lines {
+		out[i] = l.quantity
+	}
+	return out
+}
+
+// TestOrderTotal_RepeatedAdditionMatchesMultiplication covers multiplying a
+// price by a quantity (q <= 100) via repeated addition against
+// price.Mul(NewFromInt(q)).Round(2) - the shortcut createOrder actually
+// takes for a line total.
+func TestOrderTotal_RepeatedAdditionMatchesMultiplication(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		price := genPrice(t)
+		quantity := rapid.IntRange(1, 100).Draw(t, "quantity")
+
+		byAddition := decimal.Zero
+		for i := 0; i < quantity; i++ {
+			byAddition = byAddition.Add(price)
+		}
+		byAddition = byAddition.Round(2)
+
+		byMultiplication := price.Mul(decimal.NewFromInt(int64(quantity))).Round(2)
+
+		if !byAddition.Equal(byMultiplication) {
+			t.Fatalf("repeated addition (%s) != Mul/Round (%s) for price=%s quantity=%d",
+				byAddition, byMultiplication, price, quantity)
+		}
+	})
+}
+
+// FuzzParsePrice seeds the invalid strings TestInvalidPriceHandling already
+// covers, plus whitespace, a unicode minus sign, and exponent notation, and
+// asserts ParsePrice only ever returns exactly one of (decimal, nil error)
+// or (zero decimal, error) - never both a usable result and an error.
+func FuzzParsePrice(f *testing.F) {
+	seeds := []string{
+		"abc",
+		"19.99.99",
+		"",
+		"$19.99",
+		"19,99",
+		"   ",
+		"\t19.99\n",
+		"−19.99", // unicode minus sign, not ASCII '-'
+		"1e10",
+		"1.5e-3",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("ParsePrice(%q) panicked: %v", input, r)
+			}
+		}()
+
+		d, err := ParsePrice(input)
+		if err != nil && !d.IsZero() {
+			t.Fatalf("ParsePrice(%q) returned both a non-zero decimal (%s) and an error: %v", input, d, err)
+		}
+	})
+}
@@ -152,6 +152,175 @@ func TestOrderTotalCalculation(t *testing.T) {
 	}
 }
 
+// TestOrderTotalCalculation_MixedRateVAT covers a cart taxed per line rather
+// than on the grand total: a reduced-rate book alongside a standard-rate
+// shipping charge. Taxing the blended subtotal at one rate would produce a
+// different (wrong) total than taxing each line at its own rate.
+func TestOrderTotalCalculation_MixedRateVAT(t *testing.T) {
+	lines := []struct {
+		subtotal string
+		rate     string
+	}{
+		{"20.00", "0.07"}, // book, reduced rate
+		{"5.00", "0.19"},  // shipping, standard rate
+	}
+
+	tax := decimal.Zero
+	subtotal := decimal.Zero
+	for _, line := range lines {
+		amount, err := ParsePrice(line.subtotal)
+		assert.NoError(t, err)
+		rate, err := decimal.NewFromString(line.rate)
+		assert.NoError(t, err)
+
+		subtotal = subtotal.Add(amount)
+		tax = tax.Add(amount.Mul(rate).Round(2))
+	}
+
+	assert.Equal(t, "25.00", FormatPrice(subtotal))
+	assert.Equal(t, "2.35", FormatPrice(tax), "1.40 (7% of 20.00) + 0.95 (19% of 5.00)")
+	assert.Equal(t, "27.35", FormatPrice(subtotal.Add(tax)))
+
+	blended := subtotal.Mul(decimal.RequireFromString("0.19")).Round(2)
+	assert.NotEqual(t, FormatPrice(tax), FormatPrice(blended), "blended-rate tax must not equal per-line tax here")
+}
+
+// TestOrderTotalCalculation_PerLineRoundingDriftBounded proves that rounding
+// each line before summing (what the order total actually does) never drifts
+// more than a cent from rounding the whole order's unrounded sum at once.
+func TestOrderTotalCalculation_PerLineRoundingDriftBounded(t *testing.T) {
+	prices := []string{"9.995", "0.015", "3.333", "7.777", "1.001"}
+	quantity := decimal.NewFromInt(3)
+
+	perLineTotal := decimal.Zero
+	naiveTotal := decimal.Zero
+	for _, p := range prices {
+		price, err := decimal.NewFromString(p)
+		assert.NoError(t, err)
+
+		perLineTotal = perLineTotal.Add(price.Mul(quantity).Round(2))
+		naiveTotal = naiveTotal.Add(price.Mul(quantity))
+	}
+	naiveTotal = naiveTotal.Round(2)
+
+	drift := perLineTotal.Sub(naiveTotal).Abs()
+	assert.True(t, drift.LessThanOrEqual(decimal.RequireFromString("0.01")),
+		"per-line total %s drifted %s from naive whole-order total %s", perLineTotal, drift, naiveTotal)
+}
+
+// TestRoundPrice_Modes covers the tie-breaking boundary (2.675) across every
+// RoundingMode, since that's exactly where HalfUp/HalfEven/HalfDown diverge.
+func TestRoundPrice_Modes(t *testing.T) {
+	testCases := []struct {
+		mode     RoundingMode
+		amount   string
+		expected string
+	}{
+		{RoundHalfUp, "2.675", "2.68"},
+		{RoundHalfEven, "2.675", "2.68"}, // tie between 2.67/2.68; 2.68 is the even neighbor
+		{RoundHalfEven, "2.665", "2.66"}, // tie between 2.66/2.67; 2.66 is the even neighbor
+		{RoundHalfDown, "2.675", "2.67"},
+		{RoundDown, "2.679", "2.67"},
+		{RoundUp, "2.671", "2.68"},
+		{RoundHalfUp, "-2.675", "-2.68"},
+		{RoundHalfDown, "-2.675", "-2.67"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.mode)+"/"+tc.amount, func(t *testing.T) {
+			amount := decimal.RequireFromString(tc.amount)
+			assert.Equal(t, tc.expected, FormatPrice(RoundPrice(amount, tc.mode)))
+		})
+	}
+}
+
+// TestRoundPrice_HalfEvenSummationDoesNotDrift proves that, across 10k lines
+// sitting exactly on the 2dp tie boundary, HalfEven's up/down ties cancel
+// out instead of compounding the way HalfUp's always-away-from-zero ties
+// would. Alternates between a tie that rounds down (10.005, even neighbor
+// 10.00) and one that rounds up (10.015, even neighbor 10.02) so the exact
+// and rounded sums land on the same total rather than drifting line by line.
+func TestRoundPrice_HalfEvenSummationDoesNotDrift(t *testing.T) {
+	const lines = 10000
+	down := decimal.RequireFromString("10.005")
+	up := decimal.RequireFromString("10.015")
+
+	roundedTotal := decimal.Zero
+	exactTotal := decimal.Zero
+	for i := 0; i < lines; i++ {
+		lineTotal := down
+		if i%2 == 1 {
+			lineTotal = up
+		}
+		roundedTotal = roundedTotal.Add(RoundPrice(lineTotal, RoundHalfEven))
+		exactTotal = exactTotal.Add(lineTotal)
+	}
+
+	drift := roundedTotal.Sub(exactTotal).Abs()
+	assert.Truef(t, drift.LessThanOrEqual(decimal.NewFromFloat(0.01)),
+		"rounded sum drifted %s from the unrounded total across %d lines", drift, lines)
+}
+
+// TestFXRateApplication mirrors the rounding order CreateOrder uses: convert
+// the unit price first, then multiply by quantity, rather than converting
+// the line total. Rounding the whole line at the end (39.98 * 0.9231 =
+// 36.905538 -> 36.91) gives a different answer than rounding per-unit
+// (19.99 * 0.9231 = 18.45 -> * 2 = 36.90), and the per-unit order is what a
+// receipt shows.
+func TestFXRateApplication(t *testing.T) {
+	unitPrice, err := ParsePrice("19.99")
+	assert.NoError(t, err)
+	rate := decimal.RequireFromString("0.9231")
+	quantity := decimal.NewFromInt(2)
+
+	convertedUnitPrice := RoundPrice(unitPrice.Mul(rate), RoundHalfUp)
+	assert.Equal(t, "18.45", FormatPrice(convertedUnitPrice))
+
+	lineTotal := RoundPrice(convertedUnitPrice.Mul(quantity), RoundHalfUp)
+	assert.Equal(t, "36.90", FormatPrice(lineTotal))
+}
+
+// TestFXBaseTotalMatchesUnconvertedSum proves base_total_price never depends
+// on the quoted rate's precision: it's the book's-native-currency sum, so a
+// 10-decimal-place rate (NUMERIC(20,10) in the schema) can't perturb it.
+func TestFXBaseTotalMatchesUnconvertedSum(t *testing.T) {
+	items := []struct {
+		price    string
+		quantity int
+	}{
+		{"19.99", 2},
+		{"24.99", 3},
+		{"9.99", 7},
+	}
+	rate := decimal.RequireFromString("0.9231457821") // 10 decimal places
+
+	baseTotal := decimal.Zero
+	convertedTotal := decimal.Zero
+	for _, item := range items {
+		unitPrice, err := ParsePrice(item.price)
+		assert.NoError(t, err)
+		quantity := decimal.NewFromInt(int64(item.quantity))
+
+		baseLineTotal := RoundPrice(unitPrice.Mul(quantity), RoundHalfUp)
+		baseTotal = baseTotal.Add(baseLineTotal)
+
+		convertedUnitPrice := RoundPrice(unitPrice.Mul(rate), RoundHalfUp)
+		convertedTotal = convertedTotal.Add(RoundPrice(convertedUnitPrice.Mul(quantity), RoundHalfUp))
+	}
+
+	expectedBaseTotal := decimal.Zero
+	for _, item := range items {
+		unitPrice, err := ParsePrice(item.price)
+		assert.NoError(t, err)
+		expectedBaseTotal = expectedBaseTotal.Add(RoundPrice(unitPrice.Mul(decimal.NewFromInt(int64(item.quantity))), RoundHalfUp))
+	}
+
+	assert.Equal(t, FormatPrice(expectedBaseTotal), FormatPrice(baseTotal),
+		"base_total must equal round(sum(unit_price_base * qty)) regardless of the rate's precision")
+	assert.NotEqual(t, FormatPrice(baseTotal), FormatPrice(convertedTotal),
+		"sanity check: the converted total should actually differ from the base total at this rate")
+}
+
 // TestNoFloatContamination ensures we never accidentally use float64 in calculations
 func TestNoFloatContamination(t *testing.T) {
 	// Test that we can't accidentally introduce float64 precision errors
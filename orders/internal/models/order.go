@@ -1,7 +1,13 @@
 package models
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -9,25 +15,212 @@ import (
 
 type Order struct {
 	ID         int64       `json:"id" db:"id"`
+	UserID     int64       `json:"user_id,omitempty" db:"user_id"` // 0 means the order isn't associated with a user
 	Items      []OrderItem `json:"items"`
 	TotalPrice string      `json:"total_price" db:"total_price"` // Renamed from total_amount, always 2dp string
-	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	// Currency is the ISO 4217 code the order was placed (and is paid) in.
+	Currency string `json:"currency" db:"currency"`
+	// FXRate is Currency-per-book-native-currency, snapshotted once at
+	// creation via service.FXProvider so a later rate change never
+	// retroactively changes what an existing order is worth. "1" when
+	// Currency matches the books' native currency.
+	FXRate string `json:"fx_rate" db:"fx_rate"`
+	// BaseTotalPrice is TotalPrice expressed in the books' native currency
+	// (the sum of each item's BaseTotalPrice), unaffected by FXRate drift.
+	BaseTotalPrice string `json:"base_total_price" db:"base_total_price"`
+	// Adjustments are the pricing pipeline's discount/tax/shipping stages
+	// (see service/pricing), in the order they were applied. Empty when the
+	// service isn't configured with a pricing pipeline. TotalPrice already
+	// includes their sum - Items' total plus Adjustments' amounts - it's
+	// not computed from Adjustments at read time.
+	Adjustments []OrderAdjustment `json:"adjustments,omitempty"`
+	Status      OrderStatus       `json:"status" db:"status"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// OrderAdjustment is one line of a pricing pipeline's output - a discount,
+// a tax charge, or a shipping charge - persisted so an order's receipt can
+// be rendered exactly as it was computed rather than re-derived later.
+// Amount is signed (a discount's Amount is negative) and always 2dp.
+type OrderAdjustment struct {
+	ID        int64  `json:"id" db:"id"`
+	OrderID   int64  `json:"order_id" db:"order_id"`
+	Kind      string `json:"kind" db:"kind"`
+	Label     string `json:"label" db:"label"`
+	Amount    string `json:"amount" db:"amount"`
+	SortOrder int    `json:"sort_order" db:"sort_order"`
+}
+
+// OrderStatus is the order's position in its lifecycle state machine. See
+// OrderAction for the actions that move an order between statuses.
+type OrderStatus string
+
+const (
+	StatusPending   OrderStatus = "pending"
+	StatusConfirmed OrderStatus = "confirmed"
+	StatusShipped   OrderStatus = "shipped"
+	StatusDelivered OrderStatus = "delivered"
+	StatusCancelled OrderStatus = "cancelled"
+	StatusRefunded  OrderStatus = "refunded"
+)
+
+// OrderAction is a caller-requested state transition, applied through
+// OrdersService.TransitionOrder.
+type OrderAction string
+
+const (
+	ActionConfirm OrderAction = "confirm"
+	ActionShip    OrderAction = "ship"
+	ActionDeliver OrderAction = "deliver"
+	ActionCancel  OrderAction = "cancel"
+	ActionRefund  OrderAction = "refund"
+)
+
+// OrderEventKind identifies what happened in an OrderEvent row.
+type OrderEventKind string
+
+const (
+	OrderEventCreated       OrderEventKind = "order.created"
+	OrderEventItemAdded     OrderEventKind = "order.item.added"
+	OrderEventStatusChanged OrderEventKind = "order.status_changed"
+	OrderEventCancelled     OrderEventKind = "order.cancelled"
+)
+
+// NullInt64 round-trips a nullable integer through JSON as either a number
+// or null, instead of silently coercing an absent value to 0 the way a bare
+// int64 would. Used for OrderEvent.ActorID, which is unset for
+// system-initiated events.
+type NullInt64 struct {
+	Int64 int64
+	Valid bool
+}
+
+func (n NullInt64) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Int64)
+}
+
+func (n *NullInt64) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.Int64, n.Valid = 0, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.Int64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// OrderEvent is one row in an order's append-only audit log. Order creation
+// writes an order.created row in the same transaction as the insert itself;
+// item updates and status transitions write no OrderEvent rows (status
+// transitions are recorded in order_status_audit instead). Unlike events.Event (the pub/sub envelope used for
+// live WebSocket delivery), OrderEvent rows persist indefinitely and are the
+// source of truth for reconciliation and analytics. Payload is kept as raw
+// JSON rather than a fixed struct since different Kinds carry different
+// shapes (e.g. order.item.added's BookID vs order.status_changed's
+// From/To).
+type OrderEvent struct {
+	ID         int64           `json:"id" db:"id"`
+	OrderID    int64           `json:"order_id" db:"order_id"`
+	Kind       OrderEventKind  `json:"kind" db:"kind"`
+	Payload    json.RawMessage `json:"payload" db:"payload"`
+	ActorID    NullInt64       `json:"actor_id" db:"actor_id"`
+	OccurredAt time.Time       `json:"occurred_at" db:"occurred_at"`
+}
+
+// CancelOrdersForUserResponse is the body of POST /v1/users/:id/orders:cancel.
+// Cancelled lists what was actually cancelled; orders no longer pending or
+// confirmed (and so not cancellable) are silently skipped rather than failing the whole
+// request.
+type CancelOrdersForUserResponse struct {
+	Cancelled []*Order `json:"cancelled"`
+}
+
+// TransitionOrderRequest is the body of POST /v1/orders/:id/transitions.
+type TransitionOrderRequest struct {
+	Action OrderAction `json:"action" binding:"required"`
+	Reason string      `json:"reason"`
+}
+
+func (r *TransitionOrderRequest) Validate() error {
+	switch r.Action {
+	case ActionConfirm, ActionShip, ActionDeliver, ActionCancel, ActionRefund:
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+}
+
+// CancelOrderRequest is the optional body of DELETE /v1/orders/:id and
+// POST /v1/users/:id/orders:cancel. Reason is free-form and only used for
+// logging/metrics (e.g. "customer_requested", "bulk_user_cancel"); omitting
+// it is fine, a sensible default is filled in by the handler.
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+// UpdateOrderItemsRequest is the body of PATCH /v1/orders/:id. Items fully
+// replace the order's current items (no partial/merge semantics).
+// ExpectedUpdatedAt pins optimistic concurrency to the order's updated_at as
+// the client last observed it - the same compare-and-swap idea
+// TransitionOrder uses for Status, expressed over a timestamp since there's
+// no separate version counter on Order.
+type UpdateOrderItemsRequest struct {
+	Items             []CreateOrderItemRequest `json:"items" binding:"required,min=1"`
+	ExpectedUpdatedAt time.Time                `json:"expected_updated_at" binding:"required"`
+}
+
+func (r *UpdateOrderItemsRequest) Validate() error {
+	if len(r.Items) == 0 {
+		return fmt.Errorf("order must contain at least one item")
+	}
+	for i, item := range r.Items {
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+	}
+	return nil
 }
 
 type OrderItem struct {
-	ID         int64     `json:"id" db:"id"`
-	OrderID    int64     `json:"order_id" db:"order_id"`
-	BookID     int64     `json:"book_id" db:"book_id"`
-	BookTitle  string    `json:"book_title" db:"book_title"`
-	BookAuthor string    `json:"book_author" db:"book_author"`
-	Quantity   int       `json:"quantity" db:"quantity"`
-	UnitPrice  string    `json:"unit_price" db:"unit_price"`   // Always 2dp string from decimal
-	TotalPrice string    `json:"total_price" db:"total_price"` // Renamed from line_total, always 2dp string
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ID         int64  `json:"id" db:"id"`
+	OrderID    int64  `json:"order_id" db:"order_id"`
+	BookID     int64  `json:"book_id" db:"book_id"`
+	BookTitle  string `json:"book_title" db:"book_title"`
+	BookAuthor string `json:"book_author" db:"book_author"`
+	Quantity   int    `json:"quantity" db:"quantity"`
+	UnitPrice  string `json:"unit_price" db:"unit_price"`   // Always 2dp string from decimal
+	TotalPrice string `json:"total_price" db:"total_price"` // Renamed from line_total, always 2dp string
+	// Currency is the order's currency (see Order.Currency), duplicated onto
+	// each line so a line renders without joining back to its order.
+	Currency string `json:"currency" db:"currency"`
+	// FXRate is the order's snapshotted rate (see Order.FXRate).
+	FXRate string `json:"fx_rate" db:"fx_rate"`
+	// BaseUnitPrice and BaseTotalPrice are UnitPrice/TotalPrice expressed in
+	// the book's native currency, i.e. before FXRate was applied.
+	BaseUnitPrice  string    `json:"base_unit_price" db:"base_unit_price"`
+	BaseTotalPrice string    `json:"base_total_price" db:"base_total_price"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 }
 
 type CreateOrderRequest struct {
-	Items []CreateOrderItemRequest `json:"items" binding:"required,min=1"`
+	UserID int64                    `json:"user_id,omitempty"`
+	Items  []CreateOrderItemRequest `json:"items" binding:"required,min=1"`
+	// Currency is the ISO 4217 code to place the order in. Empty means the
+	// books' native currency (config.Config.DefaultCurrency), which skips
+	// the FXProvider lookup entirely.
+	Currency string `json:"currency,omitempty"`
+	// DiscountCode, when set, is resolved against the service's configured
+	// discount codes and prepended to the pricing pipeline as a
+	// pricing.DiscountModifier. Included in the idempotency request hash, so
+	// replaying a key with a different code is a conflict rather than a
+	// silent no-op.
+	DiscountCode string `json:"discount_code,omitempty"`
 }
 
 type CreateOrderItemRequest struct {
@@ -40,6 +233,10 @@ func (r *CreateOrderRequest) Validate() error {
 		return fmt.Errorf("order must contain at least one item")
 	}
 
+	if r.Currency != "" && len(r.Currency) != 3 {
+		return fmt.Errorf("currency must be a 3-letter ISO 4217 code, got %q", r.Currency)
+	}
+
 	for i, item := range r.Items {
 		if err := item.Validate(); err != nil {
 			return fmt.Errorf("item %d: %w", i+1, err)
@@ -88,6 +285,10 @@ type Book struct {
 	Description string `json:"description"`
 	Price       string `json:"price"` // String price from Books API - never use floats
 	Active      bool   `json:"active"`
+	// TaxRate is the book's VAT/GST rate (e.g. "0.07" for a reduced rate on
+	// print media), as a decimal string. Empty means "use the pricing
+	// pipeline's default rate" - most Books service responses won't set it.
+	TaxRate string `json:"tax_rate,omitempty"`
 }
 
 // GetPriceDecimal returns the price as an exact decimal for precise calculations
@@ -110,6 +311,62 @@ func ParsePrice(priceStr string) (decimal.Decimal, error) {
 	return decimal.NewFromString(priceStr)
 }
 
+// RoundingMode selects how RoundPrice resolves a tie at the 2-decimal-place
+// boundary (e.g. 2.675). Jurisdictions differ on which is required, so
+// config.Config.PriceRoundingMode makes it a deployment-time choice instead
+// of something baked into the code.
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds ties away from zero (2.675 -> 2.68). Matches the
+	// behavior this package had before RoundingMode existed.
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds ties to the nearest even digit ("banker's
+	// rounding"), the IEEE 754 default and what many European tax
+	// authorities require.
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundHalfDown rounds ties toward zero (2.675 -> 2.67).
+	RoundHalfDown RoundingMode = "half_down"
+	// RoundDown truncates toward zero regardless of the dropped digits.
+	RoundDown RoundingMode = "down"
+	// RoundUp rounds away from zero whenever any dropped digit is nonzero.
+	RoundUp RoundingMode = "up"
+)
+
+// RoundPrice rounds amount to 2 decimal places under mode. It's the single
+// place line and order totals round to a currency's minor unit, so every
+// caller observes the same tie-breaking rule instead of each hand-rolling
+// its own .Round(2).
+func RoundPrice(amount decimal.Decimal, mode RoundingMode) decimal.Decimal {
+	switch mode {
+	case RoundHalfEven:
+		return amount.RoundBank(2)
+	case RoundHalfDown:
+		return roundHalfDown(amount, 2)
+	case RoundDown:
+		return amount.Truncate(2)
+	case RoundUp:
+		return amount.RoundUp(2)
+	case RoundHalfUp:
+		fallthrough
+	default:
+		return amount.Round(2)
+	}
+}
+
+// roundHalfDown rounds half-away-from-zero at every tie except the exact
+// boundary, where it rounds toward zero instead (the mirror image of
+// decimal.Decimal.Round, which always rounds ties away from zero).
+func roundHalfDown(amount decimal.Decimal, places int32) decimal.Decimal {
+	scale := decimal.New(1, places)
+	scaled := amount.Abs().Mul(scale)
+	frac := scaled.Sub(scaled.Truncate(0))
+	if frac.Equal(decimal.NewFromFloat(0.5)) {
+		return amount.Truncate(places)
+	}
+	return amount.Round(places)
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
@@ -128,3 +385,108 @@ type PaginatedResponse[T any] struct {
 	Limit  int `json:"limit"`
 	Offset int `json:"offset"`
 }
+
+// CursorPaginationRequest carries opaque keyset pagination parameters. Cursor
+// is empty for the first page.
+type CursorPaginationRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// CursorPaginationResponse wraps keyset-paginated data. Unlike PaginatedResponse
+// it omits an exact Total unless the caller explicitly asks for one: counting
+// the full table defeats the purpose of keyset pagination. EstimatedTotal is a cheap, approximate
+// stand-in for UI hints ("about N orders") where exactness doesn't matter.
+type CursorPaginationResponse[T any] struct {
+	Data       []T    `json:"data"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	// PrevCursor pages backward to the page immediately before the one this
+	// response came from. Empty on the first page (an empty request cursor).
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	// EstimatedTotal is a planner row-count estimate (see
+	// OrdersRepository.EstimateOrderCount), omitted when unavailable.
+	EstimatedTotal *int64 `json:"estimated_total,omitempty"`
+	// Total is a real COUNT(*), populated only when the caller explicitly
+	// opted into paying for one (see OrdersRepository.CountOrdersExact).
+	Total *int64 `json:"total,omitempty"`
+}
+
+// AnomalyType identifies what kind of drift the reconciler found between an
+// order's snapshotted item and the Books service's current record of it.
+type AnomalyType string
+
+const (
+	AnomalyBookDeleted AnomalyType = "book_deleted"
+	AnomalyPriceDrift  AnomalyType = "price_drift"
+	AnomalyTitleDrift  AnomalyType = "title_drift"
+)
+
+// OrderAnomaly records one instance of drift the reconciler detected between
+// an order item's snapshotted book_title/book_author/unit_price and what the
+// Books service returns for that book_id now. Detail is a short human-
+// readable description (e.g. "unit_price 19.99, books service 24.99").
+type OrderAnomaly struct {
+	ID        int64       `json:"id" db:"id"`
+	OrderID   int64       `json:"order_id" db:"order_id"`
+	BookID    int64       `json:"book_id" db:"book_id"`
+	Type      AnomalyType `json:"type" db:"anomaly_type"`
+	Detail    string      `json:"detail" db:"detail"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// Cursor identifies a position in the (created_at, id) DESC ordering that
+// ListOrders/ListOrdersByCursor uses for keyset pagination.
+type Cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+// EncodeCursor serializes a Cursor as a base64url JSON blob suitable for a
+// `?cursor=` query parameter. When secret is non-empty, an HMAC-SHA256 tag
+// over the payload is appended (payload + "." + hex tag) so DecodeCursor can
+// reject a cursor a client has tampered with instead of running an arbitrary
+// attacker-chosen (created_at, id) through the keyset query. Pass "" to keep
+// cursors unsigned, which is the default (config.CursorSigningSecret).
+func EncodeCursor(c Cursor, secret string) string {
+	data, _ := json.Marshal(c)
+	payload := base64.URLEncoding.EncodeToString(data)
+	if secret == "" {
+		return payload
+	}
+	return payload + "." + signCursor(payload, secret)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor with the same secret.
+// An empty string decodes to the zero Cursor (meaning "start from the first
+// page"). Returns an error if secret is non-empty and the cursor's signature
+// doesn't match, or is missing entirely.
+func DecodeCursor(s string, secret string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	payload := s
+	if secret != "" {
+		parts := strings.SplitN(s, ".", 2)
+		if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(signCursor(parts[0], secret))) {
+			return Cursor{}, fmt.Errorf("invalid cursor signature")
+		}
+		payload = parts[0]
+	}
+	data, err := base64.URLEncoding.DecodeString(payload)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor payload: %w", err)
+	}
+	return c, nil
+}
+
+func signCursor(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -207,4 +208,64 @@ func TestPaginationMath(t *testing.T) {
 			assert.Equal(t, tc.itemsOnPage, itemsOnPage, "Items on page should match")
 		})
 	}
+}
+
+// TestCursorRoundTrip covers forward paging (a real cursor decodes back to
+// the same position), the end-of-range case (a zero-value Cursor survives the
+// round trip), and both with and without HMAC signing.
+func TestCursorRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cursor Cursor
+		secret string
+	}{
+		{
+			name:   "forward cursor, unsigned",
+			cursor: Cursor{CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42},
+			secret: "",
+		},
+		{
+			name:   "forward cursor, signed",
+			cursor: Cursor{CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42},
+			secret: "super-secret",
+		},
+		{
+			name:   "zero-value cursor (end of range), signed",
+			cursor: Cursor{},
+			secret: "super-secret",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded := EncodeCursor(tc.cursor, tc.secret)
+			decoded, err := DecodeCursor(encoded, tc.secret)
+			assert.NoError(t, err)
+			assert.True(t, tc.cursor.CreatedAt.Equal(decoded.CreatedAt))
+			assert.Equal(t, tc.cursor.ID, decoded.ID)
+		})
+	}
+}
+
+// TestDecodeCursor_EmptyString covers the "first page" case: no cursor
+// supplied at all decodes to the zero Cursor regardless of signing.
+func TestDecodeCursor_EmptyString(t *testing.T) {
+	decoded, err := DecodeCursor("", "super-secret")
+	assert.NoError(t, err)
+	assert.Equal(t, Cursor{}, decoded)
+}
+
+// TestDecodeCursor_TamperedSignature covers tamper detection: a cursor signed for one secret (or edited after signing)
+// must not decode successfully under a different expected secret.
+func TestDecodeCursor_TamperedSignature(t *testing.T) {
+	encoded := EncodeCursor(Cursor{CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), ID: 7}, "correct-secret")
+
+	_, err := DecodeCursor(encoded, "wrong-secret")
+	assert.Error(t, err)
+
+	_, err = DecodeCursor(encoded+"tampered", "correct-secret")
+	assert.Error(t, err)
+
+	_, err = DecodeCursor(encoded, "")
+	assert.Error(t, err, "an unsigned decode of a signed cursor should fail, not silently accept the raw payload half")
 }
\ No newline at end of file
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/metrics"
+)
+
+// Dispatcher polls Store for unpublished rows and hands each to Publisher,
+// guaranteeing at-least-once delivery: a row only gets marked published
+// after Publisher.Publish returns nil, so a crash between claim and publish
+// just leaves it for the next poll to redeliver (and, rarely, redeliver
+// twice - every downstream consumer of these events must tolerate that).
+type Dispatcher struct {
+	store        Store
+	publisher    Publisher
+	logger       *slog.Logger
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+	batchSize    int
+}
+
+// NewDispatcher builds a Dispatcher that claims up to batchSize rows at a
+// time, polling every pollInterval when there's work and backing off
+// exponentially (capped at maxBackoff) when a poll finds nothing or fails.
+func NewDispatcher(store Store, publisher Publisher, logger *slog.Logger, pollInterval, maxBackoff time.Duration, batchSize int) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		publisher:    publisher,
+		logger:       logger,
+		pollInterval: pollInterval,
+		maxBackoff:   maxBackoff,
+		batchSize:    batchSize,
+	}
+}
+
+// Run blocks, polling until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	backoff := d.pollInterval
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.InfoContext(ctx, "Outbox dispatcher stopping")
+			return
+		case <-timer.C:
+			dispatched, err := d.dispatchOnce(ctx)
+			if err != nil || dispatched == 0 {
+				backoff = nextBackoff(backoff, d.maxBackoff)
+			} else {
+				backoff = d.pollInterval
+			}
+			timer.Reset(backoff)
+		}
+	}
+}
+
+func nextBackoff(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next > max {
+		return max
+	}
+	return next
+}
+
+// dispatchOnce claims one batch and publishes what it can, leaving anything
+// Publisher.Publish failed on unpublished for the next poll to retry.
+func (d *Dispatcher) dispatchOnce(ctx context.Context) (int, error) {
+	rows, commit, err := d.store.ClaimBatch(ctx, d.batchSize)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Failed to claim outbox batch", slog.String("error", err.Error()))
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, commit(ctx, nil)
+	}
+
+	published := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		metrics.ObserveOutboxLag(time.Since(row.CreatedAt))
+
+		if err := d.publisher.Publish(ctx, row); err != nil {
+			d.logger.WarnContext(ctx, "Failed to publish outbox row, will retry",
+				slog.Int64("outbox_id", row.ID),
+				slog.Int64("aggregate_id", row.AggregateID),
+				slog.String("error", err.Error()))
+			metrics.IncOutboxFailure()
+			continue
+		}
+		published = append(published, row.ID)
+	}
+
+	if err := commit(ctx, published); err != nil {
+		d.logger.ErrorContext(ctx, "Failed to commit outbox batch", slog.String("error", err.Error()))
+		return 0, err
+	}
+
+	return len(published), nil
+}
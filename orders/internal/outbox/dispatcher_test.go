@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeStore is an in-memory Store so tests can assert exactly which rows a
+// dispatch pass claims and marks published, without a real database.
+type fakeStore struct {
+	mu        sync.Mutex
+	rows      []Row
+	claimErr  error
+	commitErr error
+	claims    atomic.Int32
+}
+
+func (s *fakeStore) ClaimBatch(ctx context.Context, limit int) ([]Row, func(ctx context.Context, publishedIDs []int64) error, error) {
+	s.claims.Add(1)
+	if s.claimErr != nil {
+		return nil, nil, s.claimErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := limit
+	if n > len(s.rows) {
+		n = len(s.rows)
+	}
+	claimed := append([]Row(nil), s.rows[:n]...)
+
+	commit := func(ctx context.Context, publishedIDs []int64) error {
+		if s.commitErr != nil {
+			return s.commitErr
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		published := make(map[int64]bool, len(publishedIDs))
+		for _, id := range publishedIDs {
+			published[id] = true
+		}
+		var remaining []Row
+		for _, r := range s.rows {
+			if !published[r.ID] {
+				remaining = append(remaining, r)
+			}
+		}
+		s.rows = remaining
+		return nil
+	}
+
+	return claimed, commit, nil
+}
+
+// fakePublisher fails for any row whose ID is in failIDs, succeeding for
+// everything else.
+type fakePublisher struct {
+	mu       sync.Mutex
+	failIDs  map[int64]bool
+	attempts []int64
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, row Row) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attempts = append(p.attempts, row.ID)
+	if p.failIDs[row.ID] {
+		return errors.New("publish failed")
+	}
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestDispatcher_PublishesAndMarksAllRows(t *testing.T) {
+	store := &fakeStore{rows: []Row{
+		{ID: 1, AggregateID: 10, EventType: "order.created", CreatedAt: time.Now()},
+		{ID: 2, AggregateID: 11, EventType: "order.created", CreatedAt: time.Now()},
+	}}
+	publisher := &fakePublisher{}
+	d := NewDispatcher(store, publisher, testLogger(), time.Second, 30*time.Second, 10)
+
+	dispatched, err := d.dispatchOnce(context.Background())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dispatched != 2 {
+		t.Fatalf("expected 2 rows dispatched, got %d", dispatched)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.rows) != 0 {
+		t.Fatalf("expected every row marked published, %d remain", len(store.rows))
+	}
+}
+
+func TestDispatcher_LeavesFailedRowsUnpublished(t *testing.T) {
+	store := &fakeStore{rows: []Row{
+		{ID: 1, AggregateID: 10, EventType: "order.created", CreatedAt: time.Now()},
+		{ID: 2, AggregateID: 11, EventType: "order.created", CreatedAt: time.Now()},
+	}}
+	publisher := &fakePublisher{failIDs: map[int64]bool{2: true}}
+	d := NewDispatcher(store, publisher, testLogger(), time.Second, 30*time.Second, 10)
+
+	dispatched, err := d.dispatchOnce(context.Background())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dispatched != 1 {
+		t.Fatalf("expected 1 row dispatched, got %d", dispatched)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.rows) != 1 || store.rows[0].ID != 2 {
+		t.Fatalf("expected row 2 to remain unpublished for retry, got %+v", store.rows)
+	}
+}
+
+func TestDispatcher_RunStopsOnContextCancellation(t *testing.T) {
+	store := &fakeStore{}
+	publisher := &fakePublisher{}
+	d := NewDispatcher(store, publisher, testLogger(), time.Millisecond, 10*time.Millisecond, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after context cancellation")
+	}
+
+	if store.claims.Load() == 0 {
+		t.Fatal("expected at least one claim before cancellation")
+	}
+}
+
+func TestDispatcher_BacksOffOnClaimError(t *testing.T) {
+	store := &fakeStore{claimErr: errors.New("db unavailable")}
+	publisher := &fakePublisher{}
+	d := NewDispatcher(store, publisher, testLogger(), time.Millisecond, time.Second, 10)
+
+	// Must not panic; a failed claim is logged and retried on the next tick.
+	dispatched, err := d.dispatchOnce(context.Background())
+	if err == nil {
+		t.Fatal("expected an error from a failing store")
+	}
+	if dispatched != 0 {
+		t.Fatalf("expected 0 dispatched on error, got %d", dispatched)
+	}
+}
+
+func TestNextBackoff_CapsAtMax(t *testing.T) {
+	got := nextBackoff(20*time.Second, 30*time.Second)
+	if got != 30*time.Second {
+		t.Fatalf("expected backoff capped at 30s, got %v", got)
+	}
+}
@@ -0,0 +1,46 @@
+// Package outbox implements the transactional-outbox pattern for order
+// lifecycle events. CreateOrderWithIdempotency (and, as they're added, other
+// mutators) writes an orders_outbox row in the same DB transaction as the
+// order change itself; Dispatcher polls that table and publishes rows
+// at-least-once, so a live subscriber being unreachable at the moment of the
+// change no longer means the event is silently dropped.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Row is one outbox entry, claimed but not yet necessarily published.
+type Row struct {
+	ID          int64
+	AggregateID int64
+	EventType   string
+	Payload     json.RawMessage
+	CreatedAt   time.Time
+}
+
+// Publisher delivers a claimed Row to whatever downstream transport backs
+// order event fan-out. Implementations must be safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, row Row) error
+}
+
+// Store is the subset of persistence Dispatcher needs.
+type Store interface {
+	// ClaimBatch selects up to limit unpublished rows with
+	// `FOR UPDATE SKIP LOCKED`, oldest first, inside a transaction it holds
+	// until the returned commit func runs. commit marks publishedIDs (a
+	// subset of the claimed rows' IDs) as published and commits; any claimed
+	// row whose ID isn't in publishedIDs is simply released unpublished for
+	// a later poll to retry.
+	ClaimBatch(ctx context.Context, limit int) (rows []Row, commit func(ctx context.Context, publishedIDs []int64) error, err error)
+}
+
+// NoopPublisher discards every row. It's the default when no downstream
+// transport (e.g. Redis) is configured, and what tests use to avoid
+// standing one up.
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(context.Context, Row) error { return nil }
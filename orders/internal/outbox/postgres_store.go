@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore is the default Store backend, reading rows CreateOrder (and
+// friends) wrote to orders_outbox in the same transaction as the order
+// change itself.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore builds a PostgresStore around an existing pool.
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+func (s *PostgresStore) ClaimBatch(ctx context.Context, limit int) ([]Row, func(ctx context.Context, publishedIDs []int64) error, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin outbox claim transaction: %w", err)
+	}
+
+	query := `
+		SELECT id, aggregate_id, event_type, payload, created_at
+		FROM orders_outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		FOR UPDATE SKIP LOCKED
+		LIMIT $1
+	`
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, nil, fmt.Errorf("failed to claim outbox batch: %w", err)
+	}
+
+	var claimed []Row
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(&r.ID, &r.AggregateID, &r.EventType, &r.Payload, &r.CreatedAt); err != nil {
+			rows.Close()
+			tx.Rollback(ctx)
+			return nil, nil, fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		claimed = append(claimed, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback(ctx)
+		return nil, nil, fmt.Errorf("error iterating outbox batch: %w", err)
+	}
+
+	commit := func(ctx context.Context, publishedIDs []int64) error {
+		defer tx.Rollback(ctx) // no-op once Commit below has already succeeded
+		if len(publishedIDs) > 0 {
+			if _, err := tx.Exec(ctx, `UPDATE orders_outbox SET published_at = NOW() WHERE id = ANY($1)`, publishedIDs); err != nil {
+				return fmt.Errorf("failed to mark outbox rows published: %w", err)
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit outbox batch: %w", err)
+		}
+		return nil
+	}
+
+	return claimed, commit, nil
+}
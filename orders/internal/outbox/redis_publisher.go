@@ -0,0 +1,33 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPublisher PUBLISHes each outbox row's payload to a channel derived
+// from its event type. It's a separate, durability-backed delivery path
+// from events.RedisPublisher, which fans out best-effort from inside the
+// request; RedisPublisher only ever sees a row once CreateOrder has already
+// durably committed it to orders_outbox.
+type RedisPublisher struct {
+	client *redis.Client
+}
+
+// NewRedisPublisher builds a RedisPublisher around an existing client.
+func NewRedisPublisher(client *redis.Client) *RedisPublisher {
+	return &RedisPublisher{client: client}
+}
+
+func outboxChannel(eventType string) string {
+	return fmt.Sprintf("orders:outbox:%s", eventType)
+}
+
+func (p *RedisPublisher) Publish(ctx context.Context, row Row) error {
+	if err := p.client.Publish(ctx, outboxChannel(row.EventType), []byte(row.Payload)).Err(); err != nil {
+		return fmt.Errorf("failed to publish outbox row to redis: %w", err)
+	}
+	return nil
+}
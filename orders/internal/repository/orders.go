@@ -2,13 +2,30 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 	"github.com/yourname/bookstore-microservices/orders/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer names spans this package starts; it's a no-op tracer until
+// telemetry.Setup registers a real TracerProvider.
+var tracer = otel.Tracer("github.com/infinity-9427/bookstore-microservices/orders/internal/repository")
+
+// startSpan starts a span named "PostgresOrdersRepository.<method>", kept
+// as a helper so every repository method below is a one-liner rather than
+// duplicating the span name prefix.
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "PostgresOrdersRepository."+method, trace.WithAttributes(attrs...))
+}
+
 type OrdersRepository interface {
 	CreateOrder(ctx context.Context, order *models.Order) error
 	CreateOrderWithIdempotency(ctx context.Context, order *models.Order, idempotencyKey string, requestHash string) error
@@ -17,15 +34,82 @@ type OrdersRepository interface {
 	CheckIdempotencyKey(ctx context.Context, idempotencyKey string, requestHash string) (*models.Order, error)
 	ListOrders(ctx context.Context) ([]*models.Order, error)
 	ListOrdersPaginated(ctx context.Context, limit, offset int) ([]*models.Order, int, error)
+	// ListOrdersByCursor returns up to limit orders positioned relative to
+	// cursor in the (created_at, id) DESC ordering (backward=false: strictly
+	// older than cursor; backward=true: the page immediately before cursor,
+	// for PrevCursor), plus whether more rows exist beyond the returned page.
+	ListOrdersByCursor(ctx context.Context, cursor models.Cursor, limit int, backward bool) ([]*models.Order, bool, error)
+	// EstimateOrderCount returns a planner estimate of the orders table's row
+	// count from pg_class.reltuples rather than a real COUNT(*), so callers
+	// paging with ListOrdersByCursor can still show an approximate total
+	// without paying for a full table scan on every page.
+	EstimateOrderCount(ctx context.Context) (int64, error)
+	// CountOrdersExact returns the orders table's real row count via COUNT(*),
+	// bounded by countOrdersExactTimeout. Reserved for callers that explicitly
+	// asked for an exact total rather than the cheap EstimateOrderCount hint.
+	CountOrdersExact(ctx context.Context) (int64, error)
+	// DeleteExpiredIdempotencyKeys removes idempotency_keys rows older than olderThan
+	// and returns the number of rows deleted. Used by the background sweep job.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, olderThan time.Time) (int64, error)
+	// TransitionOrder atomically moves an order from `from` to `to` using a
+	// compare-and-swap on status (WHERE status = from), so a lost update can't
+	// silently clobber a concurrent transition, and records an audit row.
+	// Returns *TransitionConflictError if the order's status no longer matches
+	// from by the time the update runs.
+	TransitionOrder(ctx context.Context, id int64, from, to models.OrderStatus, reason string, now time.Time) (*models.Order, error)
+	// UpdateOrderItems atomically replaces order id's items with items (and
+	// its total_price/base_total_price with totalPrice/baseTotalPrice,
+	// recomputed by the caller the same way CreateOrder computes them),
+	// succeeding only if the order is still pending and its updated_at still
+	// matches expectedUpdatedAt - a compare-and-swap expressed over the
+	// timestamp in place of a separate version counter, the same idea
+	// TransitionOrder uses for status. adjustments fully replaces the order's
+	// existing order_adjustments rows (empty if the caller has no pricing
+	// pipeline configured), and is sum-checked against totalPrice the same
+	// way CreateOrderWithIdempotency checks it at creation. Returns
+	// *OrderVersionConflictError if the CAS loses the race, or
+	// *OrderNotPendingError if the order has already moved past pending.
+	UpdateOrderItems(ctx context.Context, id int64, items []models.OrderItem, adjustments []models.OrderAdjustment, totalPrice, baseTotalPrice string, expectedUpdatedAt time.Time, now time.Time) (*models.Order, error)
+	// CancelOrdersForUser locks every one of userID's orders with
+	// SELECT ... FOR UPDATE, cancels the ones still in a cancellable status
+	// (pending or confirmed), and leaves the rest untouched - there's no
+	// per-order CAS to lose since the row lock already serializes against
+	// concurrent transitions for the duration of the transaction.
+	CancelOrdersForUser(ctx context.Context, userID int64, reason string, now time.Time) ([]*models.Order, error)
+	// ListOrderEvents returns orderID's append-only event log (newest first)
+	// plus the total row count, for the same offset-pagination headers
+	// ListOrdersPaginated powers.
+	ListOrderEvents(ctx context.Context, orderID int64, limit, offset int) ([]*models.OrderEvent, int, error)
+	// ListOrdersCreatedSince returns every order (with items) created at or
+	// after since, oldest first. Used by the reconciler to bound each pass to
+	// recently created orders rather than walking the whole table.
+	ListOrdersCreatedSince(ctx context.Context, since time.Time) ([]*models.Order, error)
+	// RecordOrderAnomaly persists one instance of drift the reconciler found
+	// between an order item's snapshot and the Books service's current record.
+	RecordOrderAnomaly(ctx context.Context, anomaly *models.OrderAnomaly) error
 }
 
 type PostgresOrdersRepository struct {
 	pool *pgxpool.Pool
+	// batchItemInsert pipelines an order's item INSERTs over a single
+	// network round trip via SendBatch instead of issuing them one at a
+	// time. See NewOrdersRepositoryWithConfig.
+	batchItemInsert bool
 }
 
 func NewOrdersRepository(pool *pgxpool.Pool) OrdersRepository {
+	return NewOrdersRepositoryWithConfig(pool, false)
+}
+
+// NewOrdersRepositoryWithConfig is NewOrdersRepository with batchItemInsert
+// explicitly set. Large carts otherwise pay one round trip per item on
+// CreateOrderWithIdempotency's insert loop; batchItemInsert pipelines them
+// over a single SendBatch call instead. Off by default so existing
+// deployments keep today's behavior until they opt in.
+func NewOrdersRepositoryWithConfig(pool *pgxpool.Pool, batchItemInsert bool) OrdersRepository {
 	return &PostgresOrdersRepository{
-		pool: pool,
+		pool:            pool,
+		batchItemInsert: batchItemInsert,
 	}
 }
 
@@ -34,6 +118,9 @@ func (r *PostgresOrdersRepository) CreateOrder(ctx context.Context, order *model
 }
 
 func (r *PostgresOrdersRepository) CreateOrderWithIdempotency(ctx context.Context, order *models.Order, idempotencyKey string, requestHash string) error {
+	ctx, span := startSpan(ctx, "CreateOrderWithIdempotency")
+	defer span.End()
+
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -46,13 +133,14 @@ func (r *PostgresOrdersRepository) CreateOrderWithIdempotency(ctx context.Contex
 		// Check if idempotency key already exists
 		var existingOrderID int64
 		var existingHash string
-		checkQuery := `SELECT order_id, request_hash FROM idempotency_keys WHERE key = $1`
-		err = tx.QueryRow(ctx, checkQuery, idempotencyKey).Scan(&existingOrderID, &existingHash)
+		var existingCreatedAt time.Time
+		checkQuery := `SELECT order_id, request_hash, created_at FROM idempotency_keys WHERE key = $1`
+		err = tx.QueryRow(ctx, checkQuery, idempotencyKey).Scan(&existingOrderID, &existingHash, &existingCreatedAt)
 
 		if err == nil {
 			// Key exists - check if request is the same
 			if existingHash != requestHash {
-				return &IdempotencyConflictError{Key: idempotencyKey}
+				return &IdempotencyConflictError{Key: idempotencyKey, ExistingHash: existingHash, CreatedAt: existingCreatedAt}
 			}
 			// Same request, fetch the existing order
 			tx.Rollback(ctx) // Clean up transaction
@@ -69,37 +157,116 @@ func (r *PostgresOrdersRepository) CreateOrderWithIdempotency(ctx context.Contex
 		// Key doesn't exist, continue with creation
 	}
 
-	// Create the order
-	orderQuery := `INSERT INTO orders (total_price) VALUES ($1) RETURNING id, created_at`
-	err = tx.QueryRow(ctx, orderQuery, order.TotalPrice).Scan(&order.ID, &order.CreatedAt)
+	// Create the order. New orders always start pending; TransitionOrder is
+	// the only way to move them forward.
+	order.Status = models.StatusPending
+	orderQuery := `INSERT INTO orders (user_id, total_price, currency, fx_rate, base_total_price, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`
+	err = tx.QueryRow(ctx, orderQuery, order.UserID, order.TotalPrice, order.Currency, order.FXRate, order.BaseTotalPrice, order.Status).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create order: %w", err)
 	}
 
 	// Create order items
 	itemQuery := `
-		INSERT INTO order_items (order_id, book_id, book_title, book_author, quantity, unit_price, total_price)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO order_items (order_id, book_id, book_title, book_author, quantity, unit_price, total_price, currency, fx_rate, base_unit_price, base_total_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`
 
-	for i := range order.Items {
-		order.Items[i].OrderID = order.ID
-		err = tx.QueryRow(ctx, itemQuery,
-			order.ID,
-			order.Items[i].BookID,
-			order.Items[i].BookTitle,
-			order.Items[i].BookAuthor,
-			order.Items[i].Quantity,
-			order.Items[i].UnitPrice,
-			order.Items[i].TotalPrice, // Renamed from LineTotal
-		).Scan(&order.Items[i].ID, &order.Items[i].CreatedAt)
+	if r.batchItemInsert {
+		if err := insertOrderItemsBatch(ctx, tx, itemQuery, order); err != nil {
+			return err
+		}
+	} else {
+		for i := range order.Items {
+			order.Items[i].OrderID = order.ID
+			err = tx.QueryRow(ctx, itemQuery,
+				order.ID,
+				order.Items[i].BookID,
+				order.Items[i].BookTitle,
+				order.Items[i].BookAuthor,
+				order.Items[i].Quantity,
+				order.Items[i].UnitPrice,
+				order.Items[i].TotalPrice, // Renamed from LineTotal
+				order.Items[i].Currency,
+				order.Items[i].FXRate,
+				order.Items[i].BaseUnitPrice,
+				order.Items[i].BaseTotalPrice,
+			).Scan(&order.Items[i].ID, &order.Items[i].CreatedAt)
 
+			if err != nil {
+				return fmt.Errorf("failed to create order item: %w", err)
+			}
+		}
+	}
+
+	// Persist pricing pipeline adjustments (discount/tax/shipping lines) and
+	// enforce, before this transaction can commit, that they reconcile with
+	// total_price: sum(item total_price) + sum(adjustment amount) must equal
+	// order.TotalPrice exactly, or the whole order is rolled back.
+	if len(order.Adjustments) > 0 {
+		lineSum := decimal.Zero
+		for _, item := range order.Items {
+			amount, err := decimal.NewFromString(item.TotalPrice)
+			if err != nil {
+				return fmt.Errorf("invalid order item total_price %q: %w", item.TotalPrice, err)
+			}
+			lineSum = lineSum.Add(amount)
+		}
+
+		adjustmentQuery := `
+			INSERT INTO order_adjustments (order_id, kind, label, amount, sort_order)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`
+		adjustmentSum := decimal.Zero
+		for i := range order.Adjustments {
+			order.Adjustments[i].OrderID = order.ID
+			order.Adjustments[i].SortOrder = i
+
+			amount, err := decimal.NewFromString(order.Adjustments[i].Amount)
+			if err != nil {
+				return fmt.Errorf("invalid adjustment amount %q: %w", order.Adjustments[i].Amount, err)
+			}
+			adjustmentSum = adjustmentSum.Add(amount)
+
+			err = tx.QueryRow(ctx, adjustmentQuery,
+				order.ID, order.Adjustments[i].Kind, order.Adjustments[i].Label, order.Adjustments[i].Amount, i,
+			).Scan(&order.Adjustments[i].ID)
+			if err != nil {
+				return fmt.Errorf("failed to create order adjustment: %w", err)
+			}
+		}
+
+		total, err := decimal.NewFromString(order.TotalPrice)
 		if err != nil {
-			return fmt.Errorf("failed to create order item: %w", err)
+			return fmt.Errorf("invalid order total_price %q: %w", order.TotalPrice, err)
+		}
+		if actual := lineSum.Add(adjustmentSum); !actual.Equal(total) {
+			return &AdjustmentSumMismatchError{OrderID: order.ID, Expected: total.String(), Actual: actual.String()}
 		}
 	}
 
+	// Record the append-only audit event in the same transaction as the
+	// order itself, so the log can never drift from what actually happened.
+	eventPayload, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order event payload: %w", err)
+	}
+	eventQuery := `INSERT INTO order_events (order_id, kind, payload, occurred_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(ctx, eventQuery, order.ID, models.OrderEventCreated, eventPayload, order.CreatedAt); err != nil {
+		return fmt.Errorf("failed to record order event: %w", err)
+	}
+
+	// Queue the same change for durable, at-least-once delivery via the
+	// outbox dispatcher. This is independent of order_events: that table is
+	// an audit log nothing ever drains, while orders_outbox is a work queue
+	// that outbox.Dispatcher claims from and eventually empties.
+	outboxQuery := `INSERT INTO orders_outbox (aggregate_id, event_type, payload, created_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(ctx, outboxQuery, order.ID, models.OrderEventCreated, eventPayload, order.CreatedAt); err != nil {
+		return fmt.Errorf("failed to enqueue outbox event: %w", err)
+	}
+
 	// Store idempotency key if provided
 	if idempotencyKey != "" {
 		idempotencyQuery := `INSERT INTO idempotency_keys (key, order_id, request_hash, created_at) VALUES ($1, $2, $3, NOW())`
@@ -112,7 +279,85 @@ func (r *PostgresOrdersRepository) CreateOrderWithIdempotency(ctx context.Contex
 	return tx.Commit(ctx)
 }
 
+// adjustmentsQuerier is the subset of *pgxpool.Pool (and pgx.Tx) that
+// attachOrderAdjustments needs, so it can be called with either depending on
+// whether the caller is already inside a transaction.
+type adjustmentsQuerier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// attachOrderAdjustments batch-fetches order_adjustments for orderIDs and
+// appends each row onto the matching order in orderMap, mirroring how order
+// items are batched across the List* methods below.
+func attachOrderAdjustments(ctx context.Context, q adjustmentsQuerier, orderIDs []int64, orderMap map[int64]*models.Order) error {
+	query := `
+		SELECT id, order_id, kind, label, amount, sort_order
+		FROM order_adjustments
+		WHERE order_id = ANY($1)
+		ORDER BY order_id, sort_order ASC
+	`
+
+	rows, err := q.Query(ctx, query, orderIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get order adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var adj models.OrderAdjustment
+		if err := rows.Scan(&adj.ID, &adj.OrderID, &adj.Kind, &adj.Label, &adj.Amount, &adj.SortOrder); err != nil {
+			return fmt.Errorf("failed to scan order adjustment: %w", err)
+		}
+		if order, exists := orderMap[adj.OrderID]; exists {
+			order.Adjustments = append(order.Adjustments, adj)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating order adjustments: %w", err)
+	}
+	return nil
+}
+
+// insertOrderItemsBatch pipelines order.Items' INSERTs over a single
+// SendBatch round trip instead of the one-round-trip-per-item loop above.
+// Postgres still executes them one statement at a time, but the driver no
+// longer waits for each RETURNING before writing the next, so this only
+// helps with network latency, not with lock contention or write volume.
+func insertOrderItemsBatch(ctx context.Context, tx pgx.Tx, itemQuery string, order *models.Order) error {
+	batch := &pgx.Batch{}
+	for i := range order.Items {
+		order.Items[i].OrderID = order.ID
+		batch.Queue(itemQuery,
+			order.ID,
+			order.Items[i].BookID,
+			order.Items[i].BookTitle,
+			order.Items[i].BookAuthor,
+			order.Items[i].Quantity,
+			order.Items[i].UnitPrice,
+			order.Items[i].TotalPrice,
+			order.Items[i].Currency,
+			order.Items[i].FXRate,
+			order.Items[i].BaseUnitPrice,
+			order.Items[i].BaseTotalPrice,
+		)
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for i := range order.Items {
+		if err := br.QueryRow().Scan(&order.Items[i].ID, &order.Items[i].CreatedAt); err != nil {
+			return fmt.Errorf("failed to create order item: %w", err)
+		}
+	}
+
+	return br.Close()
+}
+
 func (r *PostgresOrdersRepository) GetOrderByIdempotencyKey(ctx context.Context, idempotencyKey string) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "GetOrderByIdempotencyKey")
+	defer span.End()
+
 	// Get order ID from idempotency table
 	var orderID int64
 	idempotencyQuery := `SELECT order_id FROM idempotency_keys WHERE key = $1`
@@ -129,11 +374,15 @@ func (r *PostgresOrdersRepository) GetOrderByIdempotencyKey(ctx context.Context,
 }
 
 func (r *PostgresOrdersRepository) CheckIdempotencyKey(ctx context.Context, idempotencyKey string, requestHash string) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "CheckIdempotencyKey")
+	defer span.End()
+
 	// Check if idempotency key exists and get the hash
 	var existingOrderID int64
 	var existingHash string
-	checkQuery := `SELECT order_id, request_hash FROM idempotency_keys WHERE key = $1`
-	err := r.pool.QueryRow(ctx, checkQuery, idempotencyKey).Scan(&existingOrderID, &existingHash)
+	var existingCreatedAt time.Time
+	checkQuery := `SELECT order_id, request_hash, created_at FROM idempotency_keys WHERE key = $1`
+	err := r.pool.QueryRow(ctx, checkQuery, idempotencyKey).Scan(&existingOrderID, &existingHash, &existingCreatedAt)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -144,7 +393,7 @@ func (r *PostgresOrdersRepository) CheckIdempotencyKey(ctx context.Context, idem
 
 	// Key exists - check if request is the same
 	if existingHash != requestHash {
-		return nil, &IdempotencyConflictError{Key: idempotencyKey}
+		return nil, &IdempotencyConflictError{Key: idempotencyKey, ExistingHash: existingHash, CreatedAt: existingCreatedAt}
 	}
 
 	// Same request, return existing order
@@ -152,11 +401,14 @@ func (r *PostgresOrdersRepository) CheckIdempotencyKey(ctx context.Context, idem
 }
 
 func (r *PostgresOrdersRepository) GetOrderByID(ctx context.Context, id int64) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "GetOrderByID", attribute.Int64("order_id", id))
+	defer span.End()
+
 	// Get order
-	orderQuery := `SELECT id, total_price, created_at FROM orders WHERE id = $1`
+	orderQuery := `SELECT id, user_id, total_price, currency, fx_rate, base_total_price, status, created_at, updated_at FROM orders WHERE id = $1`
 
 	var order models.Order
-	err := r.pool.QueryRow(ctx, orderQuery, id).Scan(&order.ID, &order.TotalPrice, &order.CreatedAt)
+	err := r.pool.QueryRow(ctx, orderQuery, id).Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Currency, &order.FXRate, &order.BaseTotalPrice, &order.Status, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, &OrderNotFoundError{ID: id}
@@ -166,7 +418,7 @@ func (r *PostgresOrdersRepository) GetOrderByID(ctx context.Context, id int64) (
 
 	// Get order items
 	itemsQuery := `
-		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, created_at
+		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, currency, fx_rate, base_unit_price, base_total_price, created_at
 		FROM order_items
 		WHERE order_id = $1
 		ORDER BY created_at ASC
@@ -190,6 +442,10 @@ func (r *PostgresOrdersRepository) GetOrderByID(ctx context.Context, id int64) (
 			&item.Quantity,
 			&item.UnitPrice,
 			&item.TotalPrice, // Renamed from LineTotal
+			&item.Currency,
+			&item.FXRate,
+			&item.BaseUnitPrice,
+			&item.BaseTotalPrice,
 			&item.CreatedAt,
 		)
 		if err != nil {
@@ -203,12 +459,23 @@ func (r *PostgresOrdersRepository) GetOrderByID(ctx context.Context, id int64) (
 	}
 
 	order.Items = items
+
+	// Get order adjustments (discount/tax/shipping lines from the pricing
+	// pipeline, if any ran at creation time), via the same batch fetch the
+	// List* methods use below, scoped to this one order.
+	if err := attachOrderAdjustments(ctx, r.pool, []int64{order.ID}, map[int64]*models.Order{order.ID: &order}); err != nil {
+		return nil, err
+	}
+
 	return &order, nil
 }
 
 func (r *PostgresOrdersRepository) ListOrders(ctx context.Context) ([]*models.Order, error) {
+	ctx, span := startSpan(ctx, "ListOrders")
+	defer span.End()
+
 	// Get all orders
-	ordersQuery := `SELECT id, total_price, created_at FROM orders ORDER BY created_at DESC`
+	ordersQuery := `SELECT id, user_id, total_price, currency, fx_rate, base_total_price, status, created_at, updated_at FROM orders ORDER BY created_at DESC`
 
 	rows, err := r.pool.Query(ctx, ordersQuery)
 	if err != nil {
@@ -221,7 +488,7 @@ func (r *PostgresOrdersRepository) ListOrders(ctx context.Context) ([]*models.Or
 
 	for rows.Next() {
 		var order models.Order
-		err := rows.Scan(&order.ID, &order.TotalPrice, &order.CreatedAt)
+		err := rows.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Currency, &order.FXRate, &order.BaseTotalPrice, &order.Status, &order.CreatedAt, &order.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan order: %w", err)
 		}
@@ -240,7 +507,7 @@ func (r *PostgresOrdersRepository) ListOrders(ctx context.Context) ([]*models.Or
 
 	// Get all order items for these orders
 	itemsQuery := `
-		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, created_at
+		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, currency, fx_rate, base_unit_price, base_total_price, created_at
 		FROM order_items
 		WHERE order_id = ANY($1)
 		ORDER BY order_id, created_at ASC
@@ -263,6 +530,10 @@ func (r *PostgresOrdersRepository) ListOrders(ctx context.Context) ([]*models.Or
 			&item.Quantity,
 			&item.UnitPrice,
 			&item.TotalPrice, // Renamed from LineTotal
+			&item.Currency,
+			&item.FXRate,
+			&item.BaseUnitPrice,
+			&item.BaseTotalPrice,
 			&item.CreatedAt,
 		)
 		if err != nil {
@@ -278,6 +549,10 @@ func (r *PostgresOrdersRepository) ListOrders(ctx context.Context) ([]*models.Or
 		return nil, fmt.Errorf("error iterating order items: %w", err)
 	}
 
+	if err := attachOrderAdjustments(ctx, r.pool, orderIDs, orderMap); err != nil {
+		return nil, err
+	}
+
 	// Convert map to slice maintaining order
 	orders := make([]*models.Order, 0, len(orderIDs))
 	for _, id := range orderIDs {
@@ -288,6 +563,9 @@ func (r *PostgresOrdersRepository) ListOrders(ctx context.Context) ([]*models.Or
 }
 
 func (r *PostgresOrdersRepository) ListOrdersPaginated(ctx context.Context, limit, offset int) ([]*models.Order, int, error) {
+	ctx, span := startSpan(ctx, "ListOrdersPaginated", attribute.Int("limit", limit), attribute.Int("offset", offset))
+	defer span.End()
+
 	// First get total count
 	countQuery := `SELECT COUNT(*) FROM orders`
 	var total int
@@ -297,7 +575,7 @@ func (r *PostgresOrdersRepository) ListOrdersPaginated(ctx context.Context, limi
 	}
 
 	// Get paginated orders
-	ordersQuery := `SELECT id, total_price, created_at FROM orders ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	ordersQuery := `SELECT id, user_id, total_price, currency, fx_rate, base_total_price, status, created_at, updated_at FROM orders ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
 	rows, err := r.pool.Query(ctx, ordersQuery, limit, offset)
 	if err != nil {
@@ -310,7 +588,7 @@ func (r *PostgresOrdersRepository) ListOrdersPaginated(ctx context.Context, limi
 
 	for rows.Next() {
 		var order models.Order
-		err := rows.Scan(&order.ID, &order.TotalPrice, &order.CreatedAt)
+		err := rows.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Currency, &order.FXRate, &order.BaseTotalPrice, &order.Status, &order.CreatedAt, &order.UpdatedAt)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
 		}
@@ -329,7 +607,7 @@ func (r *PostgresOrdersRepository) ListOrdersPaginated(ctx context.Context, limi
 
 	// Get all order items for these orders
 	itemsQuery := `
-		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, created_at
+		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, currency, fx_rate, base_unit_price, base_total_price, created_at
 		FROM order_items
 		WHERE order_id = ANY($1)
 		ORDER BY order_id, created_at ASC
@@ -352,6 +630,10 @@ func (r *PostgresOrdersRepository) ListOrdersPaginated(ctx context.Context, limi
 			&item.Quantity,
 			&item.UnitPrice,
 			&item.TotalPrice, // Renamed from LineTotal
+			&item.Currency,
+			&item.FXRate,
+			&item.BaseUnitPrice,
+			&item.BaseTotalPrice,
 			&item.CreatedAt,
 		)
 		if err != nil {
@@ -367,6 +649,10 @@ func (r *PostgresOrdersRepository) ListOrdersPaginated(ctx context.Context, limi
 		return nil, 0, fmt.Errorf("error iterating order items: %w", err)
 	}
 
+	if err := attachOrderAdjustments(ctx, r.pool, orderIDs, orderMap); err != nil {
+		return nil, 0, err
+	}
+
 	// Convert map to slice maintaining order
 	orders := make([]*models.Order, 0, len(orderIDs))
 	for _, id := range orderIDs {
@@ -376,6 +662,532 @@ func (r *PostgresOrdersRepository) ListOrdersPaginated(ctx context.Context, limi
 	return orders, total, nil
 }
 
+// ListOrdersByCursor implements keyset pagination: it peeks one row beyond
+// limit to determine hasMore without a separate COUNT(*) query, and orders
+// by (created_at, id) DESC so ties on created_at are broken deterministically.
+//
+// backward=true fetches the page immediately before cursor (for
+// PrevCursor): it queries ascending on (created_at, id) > cursor, so the
+// results come back oldest-first and are reversed before returning, keeping
+// the DESC, newest-first contract callers rely on either way.
+func (r *PostgresOrdersRepository) ListOrdersByCursor(ctx context.Context, cursor models.Cursor, limit int, backward bool) ([]*models.Order, bool, error) {
+	ctx, span := startSpan(ctx, "ListOrdersByCursor", attribute.Int("limit", limit), attribute.Bool("backward", backward))
+	defer span.End()
+
+	var rows pgx.Rows
+	var err error
+
+	switch {
+	case cursor.CreatedAt.IsZero():
+		ordersQuery := `SELECT id, user_id, total_price, currency, fx_rate, base_total_price, status, created_at, updated_at FROM orders ORDER BY created_at DESC, id DESC LIMIT $1`
+		rows, err = r.pool.Query(ctx, ordersQuery, limit+1)
+	case backward:
+		ordersQuery := `
+			SELECT id, user_id, total_price, currency, fx_rate, base_total_price, status, created_at, updated_at FROM orders
+			WHERE (created_at, id) > ($1, $2)
+			ORDER BY created_at ASC, id ASC
+			LIMIT $3
+		`
+		rows, err = r.pool.Query(ctx, ordersQuery, cursor.CreatedAt, cursor.ID, limit+1)
+	default:
+		ordersQuery := `
+			SELECT id, user_id, total_price, currency, fx_rate, base_total_price, status, created_at, updated_at FROM orders
+			WHERE (created_at, id) < ($1, $2)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $3
+		`
+		rows, err = r.pool.Query(ctx, ordersQuery, cursor.CreatedAt, cursor.ID, limit+1)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list orders by cursor: %w", err)
+	}
+	defer rows.Close()
+
+	var orderMap = make(map[int64]*models.Order)
+	var orderIDs []int64
+
+	for rows.Next() {
+		var order models.Order
+		if err := rows.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Currency, &order.FXRate, &order.BaseTotalPrice, &order.Status, &order.CreatedAt, &order.UpdatedAt); err != nil {
+			return nil, false, fmt.Errorf("failed to scan order: %w", err)
+		}
+		order.Items = make([]models.OrderItem, 0)
+		orderMap[order.ID] = &order
+		orderIDs = append(orderIDs, order.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	hasMore := len(orderIDs) > limit
+	if hasMore {
+		orderIDs = orderIDs[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(orderIDs)-1; i < j; i, j = i+1, j-1 {
+			orderIDs[i], orderIDs[j] = orderIDs[j], orderIDs[i]
+		}
+	}
+
+	if len(orderIDs) == 0 {
+		return []*models.Order{}, false, nil
+	}
+
+	itemsQuery := `
+		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, currency, fx_rate, base_unit_price, base_total_price, created_at
+		FROM order_items
+		WHERE order_id = ANY($1)
+		ORDER BY order_id, created_at ASC
+	`
+	itemRows, err := r.pool.Query(ctx, itemsQuery, orderIDs)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get order items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item models.OrderItem
+		if err := itemRows.Scan(
+			&item.ID, &item.OrderID, &item.BookID, &item.BookTitle, &item.BookAuthor,
+			&item.Quantity, &item.UnitPrice, &item.TotalPrice,
+			&item.Currency, &item.FXRate, &item.BaseUnitPrice, &item.BaseTotalPrice, &item.CreatedAt,
+		); err != nil {
+			return nil, false, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		if order, exists := orderMap[item.OrderID]; exists {
+			order.Items = append(order.Items, item)
+		}
+	}
+	if err = itemRows.Err(); err != nil {
+		return nil, false, fmt.Errorf("error iterating order items: %w", err)
+	}
+
+	if err := attachOrderAdjustments(ctx, r.pool, orderIDs, orderMap); err != nil {
+		return nil, false, err
+	}
+
+	orders := make([]*models.Order, 0, len(orderIDs))
+	for _, id := range orderIDs {
+		orders = append(orders, orderMap[id])
+	}
+
+	return orders, hasMore, nil
+}
+
+// EstimateOrderCount reads the planner's row estimate for the orders table
+// out of pg_class rather than counting rows, so it stays cheap regardless of
+// table size. reltuples is only as fresh as the last ANALYZE/VACUUM, which is
+// fine for a UI hint but not for anything that needs to be exact.
+func (r *PostgresOrdersRepository) EstimateOrderCount(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "EstimateOrderCount")
+	defer span.End()
+
+	var estimate float64
+	query := `SELECT reltuples FROM pg_class WHERE oid = 'orders'::regclass`
+	if err := r.pool.QueryRow(ctx, query).Scan(&estimate); err != nil {
+		return 0, fmt.Errorf("failed to estimate order count: %w", err)
+	}
+	if estimate < 0 {
+		// A table that's never been analyzed reports -1, not 0.
+		return 0, nil
+	}
+	return int64(estimate), nil
+}
+
+// countOrdersExactTimeout bounds how long CountOrdersExact is allowed to hold
+// a sequential scan before giving up, since an exact COUNT(*) is the one
+// on-demand query in this repository whose cost scales with table size.
+const countOrdersExactTimeout = 3 * time.Second
+
+// CountOrdersExact returns the real row count of the orders table. Unlike
+// EstimateOrderCount it's always correct, at the cost of a full scan, so
+// callers should only reach for it when a client explicitly asked for an
+// exact total (e.g. ?count=true) rather than on every cursor-paginated page.
+func (r *PostgresOrdersRepository) CountOrdersExact(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "CountOrdersExact")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, countOrdersExactTimeout)
+	defer cancel()
+
+	var total int64
+	query := `SELECT COUNT(*) FROM orders`
+	if err := r.pool.QueryRow(ctx, query).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+	return total, nil
+}
+
+// ListOrdersCreatedSince returns every order created at or after since, items
+// included, oldest first. It follows the same two-query (orders, then a
+// batched order_items fetch) shape as ListOrders.
+func (r *PostgresOrdersRepository) ListOrdersCreatedSince(ctx context.Context, since time.Time) ([]*models.Order, error) {
+	ctx, span := startSpan(ctx, "ListOrdersCreatedSince")
+	defer span.End()
+
+	ordersQuery := `SELECT id, user_id, total_price, currency, fx_rate, base_total_price, status, created_at, updated_at FROM orders WHERE created_at >= $1 ORDER BY created_at ASC`
+	rows, err := r.pool.Query(ctx, ordersQuery, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders created since %s: %w", since, err)
+	}
+	defer rows.Close()
+
+	var orderMap = make(map[int64]*models.Order)
+	var orderIDs []int64
+
+	for rows.Next() {
+		var order models.Order
+		if err := rows.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.Currency, &order.FXRate, &order.BaseTotalPrice, &order.Status, &order.CreatedAt, &order.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		order.Items = make([]models.OrderItem, 0)
+		orderMap[order.ID] = &order
+		orderIDs = append(orderIDs, order.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	if len(orderIDs) == 0 {
+		return []*models.Order{}, nil
+	}
+
+	itemsQuery := `
+		SELECT id, order_id, book_id, book_title, book_author, quantity, unit_price, total_price, currency, fx_rate, base_unit_price, base_total_price, created_at
+		FROM order_items
+		WHERE order_id = ANY($1)
+		ORDER BY order_id, created_at ASC
+	`
+	itemRows, err := r.pool.Query(ctx, itemsQuery, orderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item models.OrderItem
+		if err := itemRows.Scan(
+			&item.ID, &item.OrderID, &item.BookID, &item.BookTitle, &item.BookAuthor,
+			&item.Quantity, &item.UnitPrice, &item.TotalPrice,
+			&item.Currency, &item.FXRate, &item.BaseUnitPrice, &item.BaseTotalPrice, &item.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		if order, exists := orderMap[item.OrderID]; exists {
+			order.Items = append(order.Items, item)
+		}
+	}
+	if err = itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order items: %w", err)
+	}
+
+	if err := attachOrderAdjustments(ctx, r.pool, orderIDs, orderMap); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*models.Order, 0, len(orderIDs))
+	for _, id := range orderIDs {
+		orders = append(orders, orderMap[id])
+	}
+	return orders, nil
+}
+
+// RecordOrderAnomaly inserts one order_anomalies row.
+func (r *PostgresOrdersRepository) RecordOrderAnomaly(ctx context.Context, anomaly *models.OrderAnomaly) error {
+	ctx, span := startSpan(ctx, "RecordOrderAnomaly")
+	defer span.End()
+
+	query := `
+		INSERT INTO order_anomalies (order_id, book_id, anomaly_type, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	return r.pool.QueryRow(ctx, query, anomaly.OrderID, anomaly.BookID, anomaly.Type, anomaly.Detail, anomaly.CreatedAt).Scan(&anomaly.ID)
+}
+
+func (r *PostgresOrdersRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, olderThan time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "DeleteExpiredIdempotencyKeys")
+	defer span.End()
+
+	query := `DELETE FROM idempotency_keys WHERE created_at < $1`
+	tag, err := r.pool.Exec(ctx, query, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// TransitionOrder implements the compare-and-swap: the UPDATE only matches
+// the row if its status still equals from, so two concurrent transitions
+// can't both apply (the loser sees RowsAffected() == 0). The audit row is
+// written in the same transaction as the status update so the two can never
+// disagree.
+func (r *PostgresOrdersRepository) TransitionOrder(ctx context.Context, id int64, from, to models.OrderStatus, reason string, now time.Time) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "TransitionOrder",
+		attribute.Int64("order_id", id), attribute.String("from", string(from)), attribute.String("to", string(to)))
+	defer span.End()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`,
+		to, now, id, from,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetOrderByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, &TransitionConflictError{ID: id, Expected: from}
+	}
+
+	auditQuery := `INSERT INTO order_status_audit (order_id, from_status, to_status, reason, created_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(ctx, auditQuery, id, from, to, reason, now); err != nil {
+		return nil, fmt.Errorf("failed to record order status audit: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit order transition: %w", err)
+	}
+
+	return r.GetOrderByID(ctx, id)
+}
+
+func (r *PostgresOrdersRepository) UpdateOrderItems(ctx context.Context, id int64, items []models.OrderItem, adjustments []models.OrderAdjustment, totalPrice, baseTotalPrice string, expectedUpdatedAt time.Time, now time.Time) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "UpdateOrderItems", attribute.Int64("order_id", id))
+	defer span.End()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE orders SET total_price = $1, base_total_price = $2, updated_at = $3 WHERE id = $4 AND updated_at = $5 AND status = $6`,
+		totalPrice, baseTotalPrice, now, id, expectedUpdatedAt, models.StatusPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order total: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		current, ferr := r.GetOrderByID(ctx, id)
+		if ferr != nil {
+			return nil, ferr
+		}
+		if current.Status != models.StatusPending {
+			return nil, &OrderNotPendingError{ID: id, Status: current.Status}
+		}
+		return nil, &OrderVersionConflictError{ID: id, ExpectedUpdatedAt: expectedUpdatedAt}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM order_items WHERE order_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to delete existing order items: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO order_items (order_id, book_id, book_title, book_author, quantity, unit_price, total_price, currency, fx_rate, base_unit_price, base_total_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+	for i := range items {
+		items[i].OrderID = id
+		err = tx.QueryRow(ctx, itemQuery,
+			id,
+			items[i].BookID,
+			items[i].BookTitle,
+			items[i].BookAuthor,
+			items[i].Quantity,
+			items[i].UnitPrice,
+			items[i].TotalPrice,
+			items[i].Currency,
+			items[i].FXRate,
+			items[i].BaseUnitPrice,
+			items[i].BaseTotalPrice,
+		).Scan(&items[i].ID, &items[i].CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order item: %w", err)
+		}
+	}
+
+	// Replace order_adjustments the same way order_items was just replaced
+	// above: updated items change line totals, so any tax/shipping adjustment
+	// computed from them is stale and must be recomputed by the caller and
+	// swapped in here, with the same sum(lines)+sum(adjustments)==total_price
+	// invariant CreateOrderWithIdempotency enforces at creation.
+	if _, err := tx.Exec(ctx, `DELETE FROM order_adjustments WHERE order_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to delete existing order adjustments: %w", err)
+	}
+
+	if len(adjustments) > 0 {
+		lineSum := decimal.Zero
+		for _, item := range items {
+			amount, err := decimal.NewFromString(item.TotalPrice)
+			if err != nil {
+				return nil, fmt.Errorf("invalid order item total_price %q: %w", item.TotalPrice, err)
+			}
+			lineSum = lineSum.Add(amount)
+		}
+
+		adjustmentQuery := `
+			INSERT INTO order_adjustments (order_id, kind, label, amount, sort_order)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`
+		adjustmentSum := decimal.Zero
+		for i := range adjustments {
+			adjustments[i].OrderID = id
+			adjustments[i].SortOrder = i
+
+			amount, err := decimal.NewFromString(adjustments[i].Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid adjustment amount %q: %w", adjustments[i].Amount, err)
+			}
+			adjustmentSum = adjustmentSum.Add(amount)
+
+			if err := tx.QueryRow(ctx, adjustmentQuery,
+				id, adjustments[i].Kind, adjustments[i].Label, adjustments[i].Amount, i,
+			).Scan(&adjustments[i].ID); err != nil {
+				return nil, fmt.Errorf("failed to create order adjustment: %w", err)
+			}
+		}
+
+		total, err := decimal.NewFromString(totalPrice)
+		if err != nil {
+			return nil, fmt.Errorf("invalid order total_price %q: %w", totalPrice, err)
+		}
+		if actual := lineSum.Add(adjustmentSum); !actual.Equal(total) {
+			return nil, &AdjustmentSumMismatchError{OrderID: id, Expected: total.String(), Actual: actual.String()}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit order item update: %w", err)
+	}
+
+	return r.GetOrderByID(ctx, id)
+}
+
+// CancelOrdersForUser locks every order_id row matching userID with
+// SELECT ... FOR UPDATE and cancels the subset still in a cancellable
+// status. Unlike TransitionOrder's single-row compare-and-swap, a bulk
+// operation over an a-priori-unknown number of rows has no single "expected"
+// status to CAS against, so the row lock does the same job: a concurrent
+// transition on any of userID's orders blocks until this transaction
+// commits, rather than racing it.
+func (r *PostgresOrdersRepository) CancelOrdersForUser(ctx context.Context, userID int64, reason string, now time.Time) ([]*models.Order, error) {
+	ctx, span := startSpan(ctx, "CancelOrdersForUser", attribute.Int64("user_id", userID))
+	defer span.End()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT id, status FROM orders WHERE user_id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock user orders: %w", err)
+	}
+
+	type cancellableOrder struct {
+		id   int64
+		from models.OrderStatus
+	}
+	var cancellable []cancellableOrder
+	for rows.Next() {
+		var id int64
+		var status models.OrderStatus
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		if status == models.StatusPending || status == models.StatusConfirmed {
+			cancellable = append(cancellable, cancellableOrder{id: id, from: status})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error iterating user orders: %w", err)
+	}
+	rows.Close()
+
+	for _, o := range cancellable {
+		if _, err := tx.Exec(ctx,
+			`UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3`,
+			models.StatusCancelled, now, o.id,
+		); err != nil {
+			return nil, fmt.Errorf("failed to cancel order %d: %w", o.id, err)
+		}
+		auditQuery := `INSERT INTO order_status_audit (order_id, from_status, to_status, reason, created_at) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := tx.Exec(ctx, auditQuery, o.id, o.from, models.StatusCancelled, reason, now); err != nil {
+			return nil, fmt.Errorf("failed to record order status audit: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk cancellation: %w", err)
+	}
+
+	cancelled := make([]*models.Order, 0, len(cancellable))
+	for _, o := range cancellable {
+		order, err := r.GetOrderByID(ctx, o.id)
+		if err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, order)
+	}
+	return cancelled, nil
+}
+
+func (r *PostgresOrdersRepository) ListOrderEvents(ctx context.Context, orderID int64, limit, offset int) ([]*models.OrderEvent, int, error) {
+	ctx, span := startSpan(ctx, "ListOrderEvents", attribute.Int64("order_id", orderID))
+	defer span.End()
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM order_events WHERE order_id = $1`, orderID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count order events: %w", err)
+	}
+
+	query := `
+		SELECT id, order_id, kind, payload, actor_id, occurred_at
+		FROM order_events
+		WHERE order_id = $1
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.pool.Query(ctx, query, orderID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list order events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.OrderEvent, 0)
+	for rows.Next() {
+		var e models.OrderEvent
+		var actorID *int64
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.Kind, &e.Payload, &actorID, &e.OccurredAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		if actorID != nil {
+			e.ActorID = models.NullInt64{Int64: *actorID, Valid: true}
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating order events: %w", err)
+	}
+
+	return events, total, nil
+}
+
 // Repository error types
 type OrderNotFoundError struct {
 	ID int64
@@ -385,10 +1197,67 @@ func (e *OrderNotFoundError) Error() string {
 	return fmt.Sprintf("order with ID %d not found", e.ID)
 }
 
+// TransitionConflictError means the order's status no longer matched Expected
+// by the time the compare-and-swap ran — either a concurrent transition won
+// the race, or the order doesn't exist (checked separately by the caller).
+type TransitionConflictError struct {
+	ID       int64
+	Expected models.OrderStatus
+}
+
+func (e *TransitionConflictError) Error() string {
+	return fmt.Sprintf("order %d is no longer in status %q", e.ID, e.Expected)
+}
+
+// OrderVersionConflictError means the order's updated_at no longer matched
+// ExpectedUpdatedAt by the time UpdateOrderItems's compare-and-swap ran -
+// another update already moved the order past the version the caller last
+// observed.
+type OrderVersionConflictError struct {
+	ID                int64
+	ExpectedUpdatedAt time.Time
+}
+
+func (e *OrderVersionConflictError) Error() string {
+	return fmt.Sprintf("order %d was modified since %s", e.ID, e.ExpectedUpdatedAt.Format(time.RFC3339))
+}
+
+// OrderNotPendingError means UpdateOrderItems was attempted against an order
+// that has already moved out of pending, so its items can no longer be
+// changed.
+type OrderNotPendingError struct {
+	ID     int64
+	Status models.OrderStatus
+}
+
+func (e *OrderNotPendingError) Error() string {
+	return fmt.Sprintf("order %d is %q, not pending", e.ID, e.Status)
+}
+
+// IdempotencyConflictError means idempotencyKey was already used with a
+// request body that hashes differently from this one. ExistingHash and
+// CreatedAt describe the original request so callers can debug the
+// mismatch (e.g. a client that reused a key after changing the payload).
 type IdempotencyConflictError struct {
-	Key string
+	Key          string
+	ExistingHash string
+	CreatedAt    time.Time
 }
 
 func (e *IdempotencyConflictError) Error() string {
-	return fmt.Sprintf("idempotency key '%s' already used with different request body", e.Key)
+	return fmt.Sprintf("idempotency key '%s' already used with different request body (original request at %s)", e.Key, e.CreatedAt.Format(time.RFC3339))
+}
+
+// AdjustmentSumMismatchError means order_adjustments plus order_items'
+// total_price didn't reconcile with the order's total_price inside the
+// transaction that wrote them (order creation or an item update) - a bug in
+// the pricing pipeline's caller, not something a client can retry past.
+type AdjustmentSumMismatchError struct {
+	OrderID  int64
+	Expected string
+	Actual   string
+}
+
+func (e *AdjustmentSumMismatchError) Error() string {
+	return fmt.Sprintf("order %d: items+adjustments sum to %s, want %s", e.OrderID, e.Actual, e.Expected)
 }
@@ -6,6 +6,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -170,6 +171,48 @@ func TestListOrdersPaginated_Empty(t *testing.T) {
 	}
 }
 
+// BenchmarkCreateOrder_NItems compares the serial and batched item-insert
+// paths as cart size grows. Run with -bench=CreateOrder_NItems; like the
+// rest of this file it needs a working pgxmock setup to actually execute.
+func BenchmarkCreateOrder_NItems(b *testing.B) {
+	for _, n := range []int{1, 10, 100, 1000} {
+		for _, batched := range []bool{false, true} {
+			name := fmt.Sprintf("items=%d/batched=%v", n, batched)
+			b.Run(name, func(b *testing.B) {
+				items := make([]models.OrderItem, n)
+				for i := range items {
+					items[i] = models.OrderItem{BookID: int64(i + 1), BookTitle: "T", BookAuthor: "A", Quantity: 1, UnitPrice: "9.99", TotalPrice: "9.99"}
+				}
+
+				for i := 0; i < b.N; i++ {
+					pool, err := pgxmock.NewPool()
+					if err != nil {
+						b.Fatalf("pgxmock: %v", err)
+					}
+
+					order := &models.Order{TotalPrice: "9.99", Items: append([]models.OrderItem(nil), items...)}
+					created := mustTime()
+
+					pool.ExpectBegin()
+					pool.ExpectQuery(`INSERT INTO orders`).WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(int64(1), created, created))
+					for range items {
+						pool.ExpectQuery(`INSERT INTO order_items`).WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(1), created))
+					}
+					pool.ExpectExec(`INSERT INTO order_events`).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+					pool.ExpectExec(`INSERT INTO orders_outbox`).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+					pool.ExpectCommit()
+
+					repo := NewOrdersRepositoryWithConfig(pool, batched)
+					if err := repo.CreateOrderWithIdempotency(context.Background(), order, "", ""); err != nil {
+						b.Fatalf("unexpected error: %v", err)
+					}
+					pool.Close()
+				}
+			})
+		}
+	}
+}
+
 func TestListOrdersPaginated_Multi(t *testing.T) {
 	pool, _ := pgxmock.NewPool()
 	defer pool.Close()
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListOrdersByCursor_EmptyPageTerminates(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(new(MockBooksClient)), WithLogger(logger), WithConfig(&config.Config{}))
+
+	mockRepo.On("ListOrdersByCursor", mock.Anything, models.Cursor{}, 20, false).Return([]*models.Order{}, false, nil)
+	mockRepo.On("EstimateOrderCount", mock.Anything).Return(int64(0), nil)
+
+	resp, err := svc.ListOrdersByCursor(context.Background(), "", 20, false, false)
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	assert.False(t, resp.HasMore)
+	assert.Empty(t, resp.NextCursor)
+	assert.Empty(t, resp.PrevCursor)
+}
+
+// TestListOrdersByCursor_StableOrderingOnTies exercises two orders created in
+// the same instant: the repository breaks the tie on id (see
+// ListOrdersByCursor's composite (created_at, id) ordering), so the cursor
+// the service hands back must carry that same id, not just the timestamp.
+func TestListOrdersByCursor_StableOrderingOnTies(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(new(MockBooksClient)), WithLogger(logger), WithConfig(&config.Config{}))
+
+	tie := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	orders := []*models.Order{
+		{ID: 102, CreatedAt: tie},
+		{ID: 101, CreatedAt: tie},
+	}
+	mockRepo.On("ListOrdersByCursor", mock.Anything, models.Cursor{}, 2, false).Return(orders, true, nil)
+	mockRepo.On("EstimateOrderCount", mock.Anything).Return(int64(2), nil)
+
+	resp, err := svc.ListOrdersByCursor(context.Background(), "", 2, false, false)
+	assert.NoError(t, err)
+	assert.True(t, resp.HasMore)
+
+	decoded, err := models.DecodeCursor(resp.NextCursor, "")
+	assert.NoError(t, err)
+	assert.True(t, tie.Equal(decoded.CreatedAt))
+	assert.Equal(t, int64(101), decoded.ID, "NextCursor must carry the last row's id to break the created_at tie")
+}
+
+// TestListOrdersByCursor_ExactCountOnlyQueriedWhenRequested proves the exact
+// COUNT(*) stays opt-in: CountOrdersExact must not be called at all unless
+// exactCount is true, and its result must land in Total rather than
+// EstimatedTotal when it is.
+func TestListOrdersByCursor_ExactCountOnlyQueriedWhenRequested(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(new(MockBooksClient)), WithLogger(logger), WithConfig(&config.Config{}))
+
+	mockRepo.On("ListOrdersByCursor", mock.Anything, models.Cursor{}, 20, false).Return([]*models.Order{}, false, nil)
+	mockRepo.On("EstimateOrderCount", mock.Anything).Return(int64(0), nil)
+
+	resp, err := svc.ListOrdersByCursor(context.Background(), "", 20, false, false)
+	assert.NoError(t, err)
+	assert.Nil(t, resp.Total)
+	mockRepo.AssertNotCalled(t, "CountOrdersExact", mock.Anything)
+
+	mockRepo.On("CountOrdersExact", mock.Anything).Return(int64(42), nil)
+	resp, err = svc.ListOrdersByCursor(context.Background(), "", 20, false, true)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp.Total) {
+		assert.Equal(t, int64(42), *resp.Total)
+	}
+}
+
+// TestListOrdersByCursor_InvalidSignatureMapsToInvalidCursorError proves a
+// tampered cursor surfaces as InvalidCursorError rather than ValidationError:
+// the two map to different HTTP statuses (400 vs 422) in the handler layer.
+func TestListOrdersByCursor_InvalidSignatureMapsToInvalidCursorError(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(new(MockBooksClient)), WithLogger(logger), WithConfig(&config.Config{CursorSigningSecret: "secret"}))
+
+	signed := models.EncodeCursor(models.Cursor{CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), ID: 1}, "wrong-secret")
+
+	_, err := svc.ListOrdersByCursor(context.Background(), signed, 20, false, false)
+	assert.Error(t, err)
+	assert.IsType(t, &InvalidCursorError{}, err)
+}
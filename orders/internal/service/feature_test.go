@@ -92,13 +92,19 @@ func (m *MockOrdersRepository) ListOrdersPaginated(ctx context.Context, limit, o
 }
 
 // TestIdempotencyFeatureFlag tests that the idempotency feature flag works correctly
+// TestIdempotencyFeatureFlag_Enabled covers the repository-level guard
+// (CheckIdempotencyKey/CreateOrderWithIdempotency) that stops two concurrent
+// requests sharing a key from creating two orders. The response-replay cache
+// (idempotency.Store) sits in front of this at the handler layer and has its
+// own replay-hit and hash-mismatch coverage in
+// internal/handlers/idempotency_store_test.go.
 func TestIdempotencyFeatureFlag_Enabled(t *testing.T) {
 	// Setup with idempotency enabled
 	mockRepo := new(MockOrdersRepository)
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true}
-	service := NewOrdersService(mockRepo, mockBooksClient, logger, cfg)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(cfg))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -147,7 +153,7 @@ func TestIdempotencyFeatureFlag_Disabled(t *testing.T) {
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: false}
-	service := NewOrdersService(mockRepo, mockBooksClient, logger, cfg)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(cfg))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -194,7 +200,7 @@ func TestListOrdersPaginated(t *testing.T) {
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true}
-	service := NewOrdersService(mockRepo, mockBooksClient, logger, cfg)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(cfg))
 
 	ctx := context.Background()
 
@@ -234,7 +240,7 @@ func TestListOrdersPaginated_EmptyResults(t *testing.T) {
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true}
-	service := NewOrdersService(mockRepo, mockBooksClient, logger, cfg)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(cfg))
 
 	ctx := context.Background()
 
@@ -267,7 +273,7 @@ func TestDecimalArithmeticAccuracy(t *testing.T) {
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: false}
-	service := NewOrdersService(mockRepo, mockBooksClient, logger, cfg)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(cfg))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
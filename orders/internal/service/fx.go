@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FXProvider resolves the exchange rate CreateOrder snapshots when an order
+// is placed in a currency other than the book's native one. The rate is
+// looked up once at creation time and stored on the order, so a later rate
+// change never retroactively changes what an existing order is worth.
+type FXProvider interface {
+	// Rate returns how many units of to one unit of from was worth at at.
+	Rate(ctx context.Context, from, to string, at time.Time) (decimal.Decimal, error)
+}
+
+// FXRateUnavailableError means no FXProvider had a rate for the requested
+// currency pair.
+type FXRateUnavailableError struct {
+	From string
+	To   string
+}
+
+func (e *FXRateUnavailableError) Error() string {
+	return fmt.Sprintf("no FX rate available for %s/%s", e.From, e.To)
+}
+
+// FixedRateProvider returns a fixed rate per (from, to) pair. It exists for
+// tests and for deployments happy with a rate that only changes on deploy,
+// as opposed to a live-quoted HTTP provider.
+type FixedRateProvider struct {
+	rates map[string]decimal.Decimal
+}
+
+// NewFixedRateProvider builds a FixedRateProvider from a "FROM/TO" -> rate
+// map, e.g. {"USD/EUR": decimal.NewFromFloat(0.9231)}.
+func NewFixedRateProvider(rates map[string]decimal.Decimal) *FixedRateProvider {
+	return &FixedRateProvider{rates: rates}
+}
+
+func (p *FixedRateProvider) Rate(ctx context.Context, from, to string, at time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	rate, ok := p.rates[from+"/"+to]
+	if !ok {
+		return decimal.Decimal{}, &FXRateUnavailableError{From: from, To: to}
+	}
+	return rate, nil
+}
+
+// HTTPFXProvider calls an external rate service at
+// config.Config.FXProviderURL. It's a stub: one unauthenticated GET, no
+// retry or circuit breaker the way clients.HTTPBooksClient has, since it's
+// not yet serving real traffic.
+type HTTPFXProvider struct {
+	http *http.Client
+	base string
+}
+
+// NewHTTPFXProvider builds an HTTPFXProvider against base (config.Config.FXProviderURL).
+func NewHTTPFXProvider(base string, timeout time.Duration) *HTTPFXProvider {
+	return &HTTPFXProvider{
+		http: &http.Client{Timeout: timeout},
+		base: base,
+	}
+}
+
+type fxRateResponse struct {
+	Rate string `json:"rate"`
+}
+
+func (p *HTTPFXProvider) Rate(ctx context.Context, from, to string, at time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	q := url.Values{"from": {from}, "to": {to}, "at": {at.Format(time.RFC3339)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.base+"/rates?"+q.Encode(), nil)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to build FX rate request: %w", err)
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("FX rate request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return decimal.Decimal{}, &FXRateUnavailableError{From: from, To: to}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return decimal.Decimal{}, fmt.Errorf("FX rate request returned status %d", resp.StatusCode)
+	}
+
+	var body fxRateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to decode FX rate response: %w", err)
+	}
+	return decimal.NewFromString(body.Rate)
+}
@@ -5,11 +5,25 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
 	"github.com/stretchr/testify/mock"
 )
 
+// MockEventPublisher provides a testify-based mock for events.EventPublisher
+// so service tests can assert on (or simply stub out) lifecycle events
+// without standing up Redis.
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, event events.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
 // MockBooksClient provides a testify-based mock for the books client used by the service layer.
 type MockBooksClient struct {
 	mock.Mock
@@ -31,6 +45,11 @@ func (m *MockBooksClient) GetBooks(ctx context.Context, bookIDs []int64) (map[in
 	return args.Get(0).(map[int64]*models.Book), args.Error(1)
 }
 
+func (m *MockBooksClient) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 // MockOrdersRepository provides a testify-based mock for the orders repository interface.
 type MockOrdersRepository struct {
 	mock.Mock
@@ -85,3 +104,71 @@ func (m *MockOrdersRepository) ListOrdersPaginated(ctx context.Context, limit, o
 	}
 	return args.Get(0).([]*models.Order), args.Get(1).(int), args.Error(2)
 }
+
+func (m *MockOrdersRepository) ListOrdersByCursor(ctx context.Context, cursor models.Cursor, limit int, backward bool) ([]*models.Order, bool, error) {
+	args := m.Called(ctx, cursor, limit, backward)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.Order), args.Bool(1), args.Error(2)
+}
+
+func (m *MockOrdersRepository) EstimateOrderCount(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrdersRepository) CountOrdersExact(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrdersRepository) ListOrdersCreatedSince(ctx context.Context, since time.Time) ([]*models.Order, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersRepository) RecordOrderAnomaly(ctx context.Context, anomaly *models.OrderAnomaly) error {
+	args := m.Called(ctx, anomaly)
+	return args.Error(0)
+}
+
+func (m *MockOrdersRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, olderThan time.Time) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrdersRepository) TransitionOrder(ctx context.Context, id int64, from, to models.OrderStatus, reason string, now time.Time) (*models.Order, error) {
+	args := m.Called(ctx, id, from, to, reason, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersRepository) UpdateOrderItems(ctx context.Context, id int64, items []models.OrderItem, adjustments []models.OrderAdjustment, totalPrice, baseTotalPrice string, expectedUpdatedAt time.Time, now time.Time) (*models.Order, error) {
+	args := m.Called(ctx, id, items, adjustments, totalPrice, baseTotalPrice, expectedUpdatedAt, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersRepository) CancelOrdersForUser(ctx context.Context, userID int64, reason string, now time.Time) ([]*models.Order, error) {
+	args := m.Called(ctx, userID, reason, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrdersRepository) ListOrderEvents(ctx context.Context, orderID int64, limit, offset int) ([]*models.OrderEvent, int, error) {
+	args := m.Called(ctx, orderID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.OrderEvent), args.Get(1).(int), args.Error(2)
+}
@@ -6,43 +6,328 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/clients"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/metrics"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/repository"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/service/pricing"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/verification"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer names spans this package starts; it's a no-op tracer until
+// telemetry.Setup registers a real TracerProvider.
+var tracer = otel.Tracer("github.com/infinity-9427/bookstore-microservices/orders/internal/service")
+
+// deadlineRemainingMS returns how many milliseconds remain before ctx's
+// deadline, or -1 if ctx carries no deadline. Used purely for log context.
+func deadlineRemainingMS(ctx context.Context) int64 {
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return -1
+	}
+	if remaining := time.Until(dl); remaining > 0 {
+		return remaining.Milliseconds()
+	}
+	return 0
+}
+
 type OrdersService interface {
 	CreateOrder(ctx context.Context, req *models.CreateOrderRequest, idempotencyKey string) (*models.Order, error)
+	// CreateSignedOrder is CreateOrder for marketplace callers that sign
+	// their request: rawBody is the exact bytes the signature covers,
+	// signature is taken from X-Signature, and signerID identifies which key
+	// to verify against. Returns SignatureInvalidError if verification fails.
+	CreateSignedOrder(ctx context.Context, req *models.CreateOrderRequest, idempotencyKey, signerID string, rawBody []byte, signature string) (*models.Order, error)
 	GetOrderByID(ctx context.Context, id int64) (*models.Order, error)
 	ListOrders(ctx context.Context) ([]*models.Order, error)
 	ListOrdersPaginated(ctx context.Context, pagination *models.PaginationRequest) (*models.PaginatedResponse[*models.Order], error)
+	// ListOrdersByCursor pages through orders by (created_at, id) DESC.
+	// backward=false (the common case) pages toward older orders from
+	// cursor; backward=true pages toward newer orders (following a
+	// PrevCursor from an earlier response). exactCount additionally populates
+	// response.Total with a real COUNT(*) instead of leaving it unset; most
+	// callers should pass false and rely on EstimatedTotal.
+	ListOrdersByCursor(ctx context.Context, cursor string, limit int, backward bool, exactCount bool) (*models.CursorPaginationResponse[*models.Order], error)
+	// TransitionOrder applies action to order id's current status (see
+	// nextStatus in transitions.go for the allowed moves), atomically via the
+	// repository's compare-and-swap, and emits an order.status_changed (or
+	// order.cancelled) event on success.
+	TransitionOrder(ctx context.Context, id int64, action models.OrderAction, reason string) (*models.Order, error)
+	// UpdateOrderItems replaces order id's items, recomputing TotalPrice with
+	// the same decimal arithmetic CreateOrder uses. Only a pending order can
+	// have its items updated; req.ExpectedUpdatedAt pins the change to the
+	// order's updated_at as the client last observed it, the same
+	// compare-and-swap idea TransitionOrder uses for status. Returns
+	// OrderNotPendingError if the order has already moved past pending, or
+	// OrderVersionConflictError if the CAS loses the race.
+	UpdateOrderItems(ctx context.Context, id int64, req *models.UpdateOrderItemsRequest) (*models.Order, error)
+	// CancelOrder is TransitionOrder with a fixed ActionCancel, surfaced as
+	// its own method so callers get OrderNotCancellableError (carrying the
+	// order's current status) instead of the generic InvalidTransitionError.
+	// Increments the orders_cancellations_total{reason} metric on success.
+	CancelOrder(ctx context.Context, id int64, reason string) (*models.Order, error)
+	// CancelOrdersForUser cancels every one of userID's orders that's still
+	// pending or confirmed, atomically with respect to concurrent
+	// transitions on the same orders, and returns the ones it cancelled.
+	// Orders no longer pending or confirmed are left alone rather than
+	// failing the whole request.
+	CancelOrdersForUser(ctx context.Context, userID int64, reason string) ([]*models.Order, error)
+	// ListOrderEvents returns orderID's append-only event log (newest first),
+	// paginated the same way ListOrdersPaginated paginates orders. Returns
+	// OrderNotFoundError if orderID doesn't exist.
+	ListOrderEvents(ctx context.Context, orderID int64, pagination *models.PaginationRequest) (*models.PaginatedResponse[*models.OrderEvent], error)
+	// RoundingMode reports the models.RoundingMode order totals round under,
+	// so handlers can echo it back to clients (e.g. X-Rounding-Mode) and
+	// reconcile totals deterministically. Falls back to RoundHalfUp if a
+	// custom PricePolicy installed via WithPricePolicy doesn't report one.
+	RoundingMode() models.RoundingMode
+}
+
+// Clock abstracts time.Now so tests can pin the timestamps the service
+// stamps onto events and CAS calls without touching the DB.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// IDGenerator mints IDs for repositories (typically in-memory ones used in
+// tests) that don't assign their own, analogous to how Clock stands in for
+// a real clock. The Postgres repository assigns IDs via RETURNING and never
+// calls this, so it's nil by default.
+type IDGenerator interface {
+	NewID() int64
+}
+
+// PricePolicy controls how line totals round to a currency's minor unit.
+// The default reproduces today's behavior (round-half-up to 2 decimal
+// places); WithPricePolicy lets callers swap in e.g. banker's rounding
+// without the rounding rule living in more than one place.
+type PricePolicy interface {
+	Round(amount decimal.Decimal) decimal.Decimal
+}
+
+type defaultPricePolicy struct{}
+
+func (defaultPricePolicy) Round(amount decimal.Decimal) decimal.Decimal {
+	return models.RoundPrice(amount, models.RoundHalfUp)
+}
+
+// configPricePolicy rounds under config.Config.PriceRoundingMode. It's what
+// NewOrdersService installs in place of defaultPricePolicy once a Config is
+// known, so deployments get their configured rounding mode without calling
+// WithPricePolicy themselves; WithPricePolicy still wins if given explicitly.
+type configPricePolicy struct {
+	mode models.RoundingMode
+}
+
+func (p configPricePolicy) Round(amount decimal.Decimal) decimal.Decimal {
+	return models.RoundPrice(amount, p.mode)
+}
+
+func (p configPricePolicy) Mode() models.RoundingMode {
+	return p.mode
 }
 
+// PostCommitHook runs after an order is durably created, once the response
+// the caller will see is already decided. A failing or slow hook must never
+// affect that response, so hooks are fire-and-forget: ordersService logs and
+// swallows whatever they do, the same way it already treats event publishing
+// as best-effort.
+type PostCommitHook func(ctx context.Context, order *models.Order)
+
 type ordersService struct {
-	repo        repository.OrdersRepository
-	booksClient clients.BooksClient
-	logger      *slog.Logger
-	config      *config.Config
+	repo            repository.OrdersRepository
+	booksClient     clients.BooksClient
+	logger          *slog.Logger
+	config          *config.Config
+	events          events.EventPublisher
+	verifier        verification.PayloadVerifier // nil disables signed-order support
+	clock           Clock
+	idGen           IDGenerator
+	pricePolicy     PricePolicy
+	fxProvider      FXProvider // nil disables multi-currency orders
+	postCommitHooks []PostCommitHook
+	pricingPipeline *pricing.Pipeline          // nil disables discount/tax/shipping adjustments
+	discountCodes   map[string]decimal.Decimal // code -> percent off, e.g. "SAVE10" -> 0.10
+}
+
+// Option configures an ordersService built by NewOrdersService. Unset
+// options fall back to today's defaults: a no-op EventPublisher, no
+// signature verifier, the real wall clock, no IDGenerator, and pricing
+// rounded under the Config's PriceRoundingMode (round-half-up when no Config
+// is given).
+type Option func(*ordersService)
+
+func WithRepository(repo repository.OrdersRepository) Option {
+	return func(s *ordersService) { s.repo = repo }
+}
+
+func WithBooksClient(booksClient clients.BooksClient) Option {
+	return func(s *ordersService) { s.booksClient = booksClient }
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *ordersService) { s.logger = logger }
+}
+
+func WithConfig(config *config.Config) Option {
+	return func(s *ordersService) { s.config = config }
+}
+
+// WithEventPublisher registers the EventPublisher that receives order
+// lifecycle events. Defaults to events.NoopPublisher{}.
+func WithEventPublisher(publisher events.EventPublisher) Option {
+	return func(s *ordersService) { s.events = publisher }
 }
 
-func NewOrdersService(
+// WithVerifier enables CreateSignedOrder. A nil verifier (the default)
+// keeps signed orders rejected with ServiceUnavailableError.
+func WithVerifier(verifier verification.PayloadVerifier) Option {
+	return func(s *ordersService) { s.verifier = verifier }
+}
+
+func WithClock(clock Clock) Option {
+	return func(s *ordersService) { s.clock = clock }
+}
+
+func WithIDGenerator(idGen IDGenerator) Option {
+	return func(s *ordersService) { s.idGen = idGen }
+}
+
+func WithPricePolicy(policy PricePolicy) Option {
+	return func(s *ordersService) { s.pricePolicy = policy }
+}
+
+// WithFXProvider enables multi-currency orders: CreateOrder resolves a rate
+// through provider whenever req.Currency differs from config.DefaultCurrency.
+// Leaving this unset (the default) keeps CreateOrder rejecting any currency
+// other than DefaultCurrency.
+func WithFXProvider(provider FXProvider) Option {
+	return func(s *ordersService) { s.fxProvider = provider }
+}
+
+// WithPostCommitHook registers a hook to run after a successful order
+// creation. Hooks fire in registration order, exactly once per created
+// order, and never run on an idempotency short-circuit or a failed create.
+// Repeatable: each call appends, so pub/sub, metrics, and the outbox writer
+// can each register independently instead of ordersService growing a field
+// per subscriber.
+func WithPostCommitHook(hook PostCommitHook) Option {
+	return func(s *ordersService) { s.postCommitHooks = append(s.postCommitHooks, hook) }
+}
+
+// WithPricingPipeline enables order adjustments: createOrder runs every
+// line through pipeline's stages (e.g. tax, shipping) and persists their
+// output as the order's Adjustments. Leaving this unset (the default) keeps
+// orders priced as a plain sum of lines, same as before this existed.
+func WithPricingPipeline(pipeline *pricing.Pipeline) Option {
+	return func(s *ordersService) { s.pricingPipeline = pipeline }
+}
+
+// WithDiscountCodes registers the discount codes CreateOrderRequest.DiscountCode
+// may reference, each mapped to a percentage off the order subtotal. A
+// request naming a code not in this map is rejected with ValidationError.
+func WithDiscountCodes(codes map[string]decimal.Decimal) Option {
+	return func(s *ordersService) { s.discountCodes = codes }
+}
+
+// NewOrdersService builds an OrdersService from the given options. Defaults
+// preserve today's behavior; see Option.
+func NewOrdersService(opts ...Option) OrdersService {
+	s := &ordersService{
+		events:      events.NoopPublisher{},
+		clock:       realClock{},
+		pricePolicy: defaultPricePolicy{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	// WithPricePolicy wins if given explicitly; otherwise derive the
+	// rounding rule from config now that every option has been applied.
+	if _, isDefault := s.pricePolicy.(defaultPricePolicy); isDefault && s.config != nil {
+		s.pricePolicy = configPricePolicy{mode: s.config.PriceRoundingMode}
+	}
+	return s
+}
+
+// NewOrdersServiceWithEvents is NewOrdersService plus an EventPublisher that
+// receives order lifecycle events (e.g. order.created, order.status_changed
+// and order.cancelled). Pass events.NoopPublisher{} to
+// opt out, which is what NewOrdersService does.
+func NewOrdersServiceWithEvents(
 	repo repository.OrdersRepository,
 	booksClient clients.BooksClient,
 	logger *slog.Logger,
 	config *config.Config,
+	publisher events.EventPublisher,
 ) OrdersService {
-	return &ordersService{
-		repo:        repo,
-		booksClient: booksClient,
-		logger:      logger,
-		config:      config,
-	}
+	return NewOrdersService(
+		WithRepository(repo),
+		WithBooksClient(booksClient),
+		WithLogger(logger),
+		WithConfig(config),
+		WithEventPublisher(publisher),
+	)
 }
 
+// NewOrdersServiceWithVerifier is NewOrdersServiceWithEvents plus a
+// PayloadVerifier for CreateSignedOrder. Pass a nil verifier to keep signed
+// orders rejected with ServiceUnavailableError, which is what
+// NewOrdersServiceWithEvents does.
+func NewOrdersServiceWithVerifier(
+	repo repository.OrdersRepository,
+	booksClient clients.BooksClient,
+	logger *slog.Logger,
+	config *config.Config,
+	publisher events.EventPublisher,
+	verifier verification.PayloadVerifier,
+) OrdersService {
+	return NewOrdersService(
+		WithRepository(repo),
+		WithBooksClient(booksClient),
+		WithLogger(logger),
+		WithConfig(config),
+		WithEventPublisher(publisher),
+		WithVerifier(verifier),
+	)
+}
+
+// CreateOrder creates an unsigned order. See CreateSignedOrder for the
+// marketplace flow that verifies a signed payload first.
 func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrderRequest, idempotencyKey string) (*models.Order, error) {
+	return s.createOrder(ctx, req, idempotencyKey)
+}
+
+// CreateSignedOrder verifies the signed payload before delegating to the
+// same creation path CreateOrder uses.
+func (s *ordersService) CreateSignedOrder(ctx context.Context, req *models.CreateOrderRequest, idempotencyKey, signerID string, rawBody []byte, signature string) (*models.Order, error) {
+	if s.verifier == nil {
+		return nil, &ServiceUnavailableError{Message: "signed orders are not enabled on this service"}
+	}
+	if err := s.verifier.Verify(ctx, signerID, rawBody, signature); err != nil {
+		s.logger.WarnContext(ctx, "Signed order rejected",
+			slog.String("signer_id", signerID),
+			slog.String("error", err.Error()))
+		return nil, &SignatureInvalidError{Reason: err.Error()}
+	}
+	return s.createOrder(ctx, req, idempotencyKey)
+}
+
+func (s *ordersService) createOrder(ctx context.Context, req *models.CreateOrderRequest, idempotencyKey string) (*models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrdersService.CreateOrder")
+	defer span.End()
+
 	requestID := ctx.Value("request_id")
 	if requestID == nil {
 		requestID = "unknown"
@@ -51,7 +336,8 @@ func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrder
 	s.logger.InfoContext(ctx, "Creating order",
 		slog.String("request_id", fmt.Sprintf("%v", requestID)),
 		slog.Int("item_count", len(req.Items)),
-		slog.String("idempotency_key", idempotencyKey))
+		slog.String("idempotency_key", idempotencyKey),
+		slog.Int64("deadline_remaining_ms", deadlineRemainingMS(ctx)))
 
 	// Normalize the request (sum duplicate book IDs)
 	if err := req.Validate(); err != nil {
@@ -61,19 +347,40 @@ func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrder
 	// Check idempotency first - only if feature is enabled
 	var requestHash string
 	if s.config.IdempotencyEnabled && idempotencyKey != "" {
-		// Create hash of the original request
-		requestData, _ := json.Marshal(req.Items)
+		// Create hash of the original request. DiscountCode is included so
+		// replaying a key with a different code is a conflict, not a silent
+		// no-op that returns the first code's adjustments.
+		requestData, _ := json.Marshal(struct {
+			Items        []models.CreateOrderItemRequest `json:"items"`
+			DiscountCode string                          `json:"discount_code,omitempty"`
+		}{Items: req.Items, DiscountCode: req.DiscountCode})
 		hash := sha256.Sum256(requestData)
 		requestHash = fmt.Sprintf("%x", hash)
 
 		if existingOrder, err := s.repo.CheckIdempotencyKey(ctx, idempotencyKey, requestHash); err == nil {
+			span.SetAttributes(attribute.Bool("orders.idempotency.hit", true))
 			s.logger.InfoContext(ctx, "Returning existing order for idempotency key",
 				slog.String("request_id", fmt.Sprintf("%v", requestID)),
 				slog.String("idempotency_key", idempotencyKey),
 				slog.Int64("order_id", existingOrder.ID))
+
+			// Best-effort, same as a real CreateOrder: a dropped event
+			// doesn't change what the caller gets back.
+			if err := s.events.Publish(ctx, events.Event{
+				Type:      events.OrderReplayed,
+				OrderID:   existingOrder.ID,
+				UserID:    existingOrder.UserID,
+				Payload:   existingOrder,
+				Timestamp: s.clock.Now(),
+			}); err != nil {
+				s.logger.WarnContext(ctx, "Failed to publish order.replayed event",
+					slog.Int64("order_id", existingOrder.ID),
+					slog.String("error", err.Error()))
+			}
+
 			return existingOrder, nil
 		} else if conflictErr, ok := err.(*repository.IdempotencyConflictError); ok {
-			return nil, &IdempotencyConflictError{Key: conflictErr.Key}
+			return nil, &IdempotencyConflictError{Key: conflictErr.Key, ExistingHash: conflictErr.ExistingHash, CreatedAt: conflictErr.CreatedAt}
 		} else if _, ok := err.(*repository.OrderNotFoundError); !ok {
 			// Some other error occurred
 			s.logger.ErrorContext(ctx, "Failed to check idempotency",
@@ -94,6 +401,13 @@ func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrder
 		}
 	}
 
+	// The client may have already gone away while we were validating and
+	// checking idempotency; skip the (expensive) Books fetch if so.
+	if ctx.Err() != nil {
+		metrics.IncRequestCancelled("books")
+		return nil, &ClientCancelledError{Stage: "books"}
+	}
+
 	s.logger.InfoContext(ctx, "Validating books with Books service",
 		slog.String("request_id", fmt.Sprintf("%v", requestID)),
 		slog.Any("book_ids", bookIDs))
@@ -129,9 +443,42 @@ func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrder
 		slog.String("request_id", fmt.Sprintf("%v", requestID)),
 		slog.Int("books_validated", len(books)))
 
+	// Resolve the order's currency once, up front: every line snapshots the
+	// same rate, so a rate change mid-request can never make two lines on
+	// the same order disagree about what currency they're in.
+	bookCurrency := s.defaultCurrency()
+	orderCurrency := req.Currency
+	if orderCurrency == "" {
+		orderCurrency = bookCurrency
+	}
+
+	fxRate := decimal.NewFromInt(1)
+	if orderCurrency != bookCurrency {
+		if s.fxProvider == nil {
+			return nil, &ServiceUnavailableError{Message: "multi-currency orders are not enabled on this service"}
+		}
+		fxRate, err = s.fxProvider.Rate(ctx, bookCurrency, orderCurrency, s.clock.Now())
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to resolve FX rate",
+				slog.String("request_id", fmt.Sprintf("%v", requestID)),
+				slog.String("from", bookCurrency), slog.String("to", orderCurrency),
+				slog.String("error", err.Error()))
+			return nil, &ServiceUnavailableError{Message: err.Error()}
+		}
+	}
+
+	// A discount code only means anything once a pricing pipeline is wired up
+	// to apply it (see WithPricingPipeline); without one, fail closed instead
+	// of silently creating a full-price order, same as the FX check above
+	// does for a currency with no fxProvider configured.
+	if req.DiscountCode != "" && s.pricingPipeline == nil {
+		return nil, &ServiceUnavailableError{Message: "discount codes are not enabled on this service"}
+	}
+
 	// Calculate totals using exact decimal arithmetic - NO FLOATS
 	orderItems := make([]models.OrderItem, 0, len(req.Items))
 	orderTotal := decimal.Zero
+	baseOrderTotal := decimal.Zero
 
 	for _, itemReq := range req.Items {
 		book := books[itemReq.BookID]
@@ -147,26 +494,93 @@ func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrder
 			return nil, &InternalError{Message: "Invalid book price format"}
 		}
 
-		// Exact decimal multiplication: price × quantity
+		// Exact decimal multiplication: price × quantity, in the book's
+		// native currency.
 		quantity := decimal.NewFromInt(int64(itemReq.Quantity))
-		lineTotal := unitPrice.Mul(quantity).Round(2)
+		baseLineTotal := s.pricePolicy.Round(unitPrice.Mul(quantity))
+		baseOrderTotal = baseOrderTotal.Add(baseLineTotal)
+
+		// Convert the unit price first, then multiply by quantity, so a
+		// rounded-per-unit price matches what a receipt would show rather
+		// than rounding the whole line at the end.
+		convertedUnitPrice := s.pricePolicy.Round(unitPrice.Mul(fxRate))
+		lineTotal := s.pricePolicy.Round(convertedUnitPrice.Mul(quantity))
 		orderTotal = orderTotal.Add(lineTotal)
 
 		orderItem := models.OrderItem{
-			BookID:     itemReq.BookID,
-			BookTitle:  book.Title,
-			BookAuthor: book.Author,
-			Quantity:   itemReq.Quantity,
-			UnitPrice:  models.FormatPrice(unitPrice), // Always 2dp string
-			TotalPrice: models.FormatPrice(lineTotal), // Renamed from LineTotal, always 2dp string
+			BookID:         itemReq.BookID,
+			BookTitle:      book.Title,
+			BookAuthor:     book.Author,
+			Quantity:       itemReq.Quantity,
+			UnitPrice:      models.FormatPrice(convertedUnitPrice), // Always 2dp string
+			TotalPrice:     models.FormatPrice(lineTotal),          // Renamed from LineTotal, always 2dp string
+			Currency:       orderCurrency,
+			FXRate:         fxRate.String(),
+			BaseUnitPrice:  models.FormatPrice(unitPrice),
+			BaseTotalPrice: models.FormatPrice(baseLineTotal),
 		}
 		orderItems = append(orderItems, orderItem)
 	}
 
+	// Run the pricing pipeline (discount/tax/shipping) on top of the line
+	// total, if this service instance is configured with one. A nil
+	// pipeline leaves orderTotal as the plain sum of lines, same as before
+	// adjustments existed.
+	var adjustments []models.OrderAdjustment
+	if s.pricingPipeline != nil {
+		lines := make([]pricing.Line, len(orderItems))
+		for i, item := range orderItems {
+			lineTotal, err := decimal.NewFromString(item.TotalPrice)
+			if err != nil {
+				return nil, &InternalError{Message: "Invalid order item total for pricing"}
+			}
+			taxRate := decimal.Zero
+			if book := books[item.BookID]; book != nil && book.TaxRate != "" {
+				if rate, err := decimal.NewFromString(book.TaxRate); err == nil {
+					taxRate = rate
+				}
+			}
+			lines[i] = pricing.Line{Subtotal: lineTotal, TaxRate: taxRate}
+		}
+
+		modifiers := s.pricingPipeline.Modifiers
+		if req.DiscountCode != "" {
+			percent, ok := s.discountCodes[req.DiscountCode]
+			if !ok {
+				return nil, &ValidationError{Message: fmt.Sprintf("unknown discount code %q", req.DiscountCode)}
+			}
+			discount := pricing.DiscountModifier{Label: "Discount: " + req.DiscountCode, Percent: percent}
+			modifiers = append([]pricing.PriceModifier{discount}, modifiers...)
+		}
+
+		result := (pricing.Pipeline{Modifiers: modifiers}).Run(lines)
+		orderTotal = result.Total
+		adjustments = make([]models.OrderAdjustment, len(result.Adjustments))
+		for i, adj := range result.Adjustments {
+			adjustments[i] = models.OrderAdjustment{
+				Kind:      adj.Kind,
+				Label:     adj.Label,
+				Amount:    models.FormatPrice(adj.Amount),
+				SortOrder: i,
+			}
+		}
+	}
+
 	// Create the order with all calculated values
 	order := &models.Order{
-		Items:      orderItems,
-		TotalPrice: models.FormatPrice(orderTotal), // Renamed from TotalAmount, always 2dp string
+		UserID:         req.UserID,
+		Items:          orderItems,
+		TotalPrice:     models.FormatPrice(orderTotal), // Renamed from TotalAmount, always 2dp string
+		Currency:       orderCurrency,
+		FXRate:         fxRate.String(),
+		BaseTotalPrice: models.FormatPrice(baseOrderTotal),
+		Adjustments:    adjustments,
+	}
+
+	// The Books round-trip can itself eat the whole remaining budget.
+	if ctx.Err() != nil {
+		metrics.IncRequestCancelled("db")
+		return nil, &ClientCancelledError{Stage: "db"}
 	}
 
 	// Now begin transaction and create order
@@ -177,12 +591,12 @@ func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrder
 		createErr = s.repo.CreateOrder(ctx, order)
 	}
 	if createErr != nil {
-		switch createErr.(type) {
+		switch e := createErr.(type) {
 		case *repository.IdempotencyConflictError:
 			s.logger.WarnContext(ctx, "Idempotency key conflict",
 				slog.String("request_id", fmt.Sprintf("%v", requestID)),
 				slog.String("idempotency_key", idempotencyKey))
-			return nil, &IdempotencyConflictError{Key: idempotencyKey}
+			return nil, &IdempotencyConflictError{Key: idempotencyKey, ExistingHash: e.ExistingHash, CreatedAt: e.CreatedAt}
 		default:
 			s.logger.ErrorContext(ctx, "Failed to create order",
 				slog.String("request_id", fmt.Sprintf("%v", requestID)),
@@ -197,10 +611,239 @@ func (s *ordersService) CreateOrder(ctx context.Context, req *models.CreateOrder
 		slog.String("total_price", order.TotalPrice), // Updated field name
 		slog.String("idempotency_key", idempotencyKey))
 
+	// Best-effort: a dropped event doesn't fail order creation, since the
+	// order is already durably committed at this point.
+	if err := s.events.Publish(ctx, events.Event{
+		Type:      events.OrderCreated,
+		OrderID:   order.ID,
+		UserID:    order.UserID,
+		Payload:   order,
+		Timestamp: s.clock.Now(),
+	}); err != nil {
+		s.logger.WarnContext(ctx, "Failed to publish order.created event",
+			slog.Int64("order_id", order.ID),
+			slog.String("error", err.Error()))
+	}
+
+	// Hooks run after the order is already committed and the response is
+	// decided, so they can't fail order creation the way a mid-transaction
+	// error can.
+	for _, hook := range s.postCommitHooks {
+		hook(ctx, order)
+	}
+
 	return order, nil
 }
 
+// UpdateOrderItems re-validates req.Items against the Books service and
+// recomputes TotalPrice the same way createOrder does, then asks the
+// repository to swap them in under optimistic concurrency. Unlike createOrder
+// there's no idempotency key: a PATCH is not a create, so replaying it is the
+// client's responsibility.
+func (s *ordersService) UpdateOrderItems(ctx context.Context, id int64, req *models.UpdateOrderItemsRequest) (*models.Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+
+	bookIDs := make([]int64, 0, len(req.Items))
+	seen := make(map[int64]bool)
+	for _, item := range req.Items {
+		if !seen[item.BookID] {
+			seen[item.BookID] = true
+			bookIDs = append(bookIDs, item.BookID)
+		}
+	}
+
+	books, err := s.booksClient.GetBooks(ctx, bookIDs)
+	if err != nil {
+		switch e := err.(type) {
+		case *clients.BookNotFoundError:
+			return nil, &BookNotFoundError{BookID: e.BookID}
+		case *clients.BookInactiveError:
+			return nil, &BookNotOrderableError{BookID: e.BookID}
+		case *clients.CircuitBreakerError, *clients.ServiceUnavailableError:
+			return nil, &ServiceUnavailableError{Message: e.Error()}
+		default:
+			return nil, &ServiceUnavailableError{Message: "Books service error: " + err.Error()}
+		}
+	}
+	for _, bookID := range bookIDs {
+		if _, found := books[bookID]; !found {
+			return nil, &BookNotFoundError{BookID: bookID}
+		}
+	}
+
+	// A PATCH keeps the order's currency and snapshotted rate as they were
+	// at creation - only createOrder resolves a fresh rate.
+	existing, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		if _, ok := err.(*repository.OrderNotFoundError); ok {
+			return nil, &OrderNotFoundError{ID: id}
+		}
+		return nil, &InternalError{Message: "Failed to load order"}
+	}
+	fxRate, err := decimal.NewFromString(existing.FXRate)
+	if err != nil {
+		fxRate = decimal.NewFromInt(1)
+	}
+
+	// Exact decimal arithmetic - no floats, same as createOrder.
+	items := make([]models.OrderItem, 0, len(req.Items))
+	orderTotal := decimal.Zero
+	baseOrderTotal := decimal.Zero
+	for _, itemReq := range req.Items {
+		book := books[itemReq.BookID]
+		unitPrice, err := book.GetPriceDecimal()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Invalid price format",
+				slog.Int64("book_id", book.ID), slog.String("price", book.Price), slog.String("error", err.Error()))
+			return nil, &InternalError{Message: "Invalid book price format"}
+		}
+
+		quantity := decimal.NewFromInt(int64(itemReq.Quantity))
+		baseLineTotal := s.pricePolicy.Round(unitPrice.Mul(quantity))
+		baseOrderTotal = baseOrderTotal.Add(baseLineTotal)
+
+		convertedUnitPrice := s.pricePolicy.Round(unitPrice.Mul(fxRate))
+		lineTotal := s.pricePolicy.Round(convertedUnitPrice.Mul(quantity))
+		orderTotal = orderTotal.Add(lineTotal)
+
+		items = append(items, models.OrderItem{
+			BookID:         itemReq.BookID,
+			BookTitle:      book.Title,
+			BookAuthor:     book.Author,
+			Quantity:       itemReq.Quantity,
+			UnitPrice:      models.FormatPrice(convertedUnitPrice),
+			TotalPrice:     models.FormatPrice(lineTotal),
+			Currency:       existing.Currency,
+			FXRate:         existing.FXRate,
+			BaseUnitPrice:  models.FormatPrice(unitPrice),
+			BaseTotalPrice: models.FormatPrice(baseLineTotal),
+		})
+	}
+
+	// Re-run the pricing pipeline against the new line totals, same as
+	// createOrder: tax and shipping are derived purely from the lines and go
+	// stale the moment an item changes, so they must be recomputed here
+	// rather than left as whatever they were when the order was first
+	// created. A discount code isn't re-entered on a PATCH, but if the order
+	// already carries a discount adjustment it must still be re-applied at
+	// its original percentage - otherwise editing items would silently
+	// un-discount the order.
+	var adjustments []models.OrderAdjustment
+	if s.pricingPipeline != nil {
+		lines := make([]pricing.Line, len(items))
+		for i, item := range items {
+			lineTotal, err := decimal.NewFromString(item.TotalPrice)
+			if err != nil {
+				return nil, &InternalError{Message: "Invalid order item total for pricing"}
+			}
+			taxRate := decimal.Zero
+			if book := books[item.BookID]; book != nil && book.TaxRate != "" {
+				if rate, err := decimal.NewFromString(book.TaxRate); err == nil {
+					taxRate = rate
+				}
+			}
+			lines[i] = pricing.Line{Subtotal: lineTotal, TaxRate: taxRate}
+		}
+
+		modifiers := s.pricingPipeline.Modifiers
+		if discount := existingDiscountAdjustment(existing.Adjustments); discount != nil {
+			percent, err := recoverDiscountPercent(existing.Items, *discount)
+			if err != nil {
+				return nil, &InternalError{Message: "Failed to recover discount percentage: " + err.Error()}
+			}
+			modifiers = append([]pricing.PriceModifier{pricing.DiscountModifier{Label: discount.Label, Percent: percent}}, modifiers...)
+		}
+
+		result := (pricing.Pipeline{Modifiers: modifiers}).Run(lines)
+		orderTotal = result.Total
+		adjustments = make([]models.OrderAdjustment, len(result.Adjustments))
+		for i, adj := range result.Adjustments {
+			adjustments[i] = models.OrderAdjustment{
+				Kind:      adj.Kind,
+				Label:     adj.Label,
+				Amount:    models.FormatPrice(adj.Amount),
+				SortOrder: i,
+			}
+		}
+	}
+
+	updated, err := s.repo.UpdateOrderItems(ctx, id, items, adjustments, models.FormatPrice(orderTotal), models.FormatPrice(baseOrderTotal), req.ExpectedUpdatedAt, s.clock.Now())
+	if err != nil {
+		switch e := err.(type) {
+		case *repository.OrderVersionConflictError:
+			return nil, &OrderVersionConflictError{ID: e.ID, ExpectedUpdatedAt: e.ExpectedUpdatedAt}
+		case *repository.OrderNotPendingError:
+			return nil, &OrderNotPendingError{ID: e.ID, Status: e.Status}
+		case *repository.OrderNotFoundError:
+			return nil, &OrderNotFoundError{ID: id}
+		default:
+			s.logger.ErrorContext(ctx, "Failed to update order items",
+				slog.Int64("order_id", id), slog.String("error", err.Error()))
+			return nil, &InternalError{Message: "Failed to update order items"}
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Order items updated",
+		slog.Int64("order_id", id), slog.String("total_price", updated.TotalPrice))
+
+	// Best-effort, same as CreateOrder/TransitionOrder: a dropped event
+	// doesn't undo an already-committed update.
+	if err := s.events.Publish(ctx, events.Event{
+		Type:      events.OrderItemsUpdated,
+		OrderID:   id,
+		UserID:    updated.UserID,
+		Payload:   updated,
+		Timestamp: s.clock.Now(),
+	}); err != nil {
+		s.logger.WarnContext(ctx, "Failed to publish order.items_updated event",
+			slog.Int64("order_id", id), slog.String("error", err.Error()))
+	}
+
+	return updated, nil
+}
+
+// existingDiscountAdjustment returns order id's discount adjustment, if it
+// has one, so UpdateOrderItems can carry it forward across an item edit.
+func existingDiscountAdjustment(adjustments []models.OrderAdjustment) *models.OrderAdjustment {
+	for i := range adjustments {
+		if adjustments[i].Kind == pricing.KindDiscount {
+			return &adjustments[i]
+		}
+	}
+	return nil
+}
+
+// recoverDiscountPercent reconstructs the flat percentage a previously
+// applied DiscountModifier used, from its persisted Amount and the order's
+// line totals as they were before this update - order_adjustments only
+// stores the resulting amount, not the percent that produced it.
+func recoverDiscountPercent(items []models.OrderItem, discount models.OrderAdjustment) (decimal.Decimal, error) {
+	subtotal := decimal.Zero
+	for _, item := range items {
+		amount, err := decimal.NewFromString(item.TotalPrice)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("invalid order item total_price %q: %w", item.TotalPrice, err)
+		}
+		subtotal = subtotal.Add(amount)
+	}
+	if subtotal.IsZero() {
+		return decimal.Zero, fmt.Errorf("order had a discount adjustment but zero subtotal")
+	}
+
+	amount, err := decimal.NewFromString(discount.Amount)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid discount adjustment amount %q: %w", discount.Amount, err)
+	}
+
+	return amount.Neg().Div(subtotal), nil
+}
+
 func (s *ordersService) GetOrderByID(ctx context.Context, id int64) (*models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrdersService.GetOrderByID", trace.WithAttributes(attribute.Int64("order_id", id)))
+	defer span.End()
+
 	order, err := s.repo.GetOrderByID(ctx, id)
 	if err != nil {
 		switch err.(type) {
@@ -229,6 +872,10 @@ func (s *ordersService) ListOrders(ctx context.Context) ([]*models.Order, error)
 }
 
 func (s *ordersService) ListOrdersPaginated(ctx context.Context, pagination *models.PaginationRequest) (*models.PaginatedResponse[*models.Order], error) {
+	ctx, span := tracer.Start(ctx, "OrdersService.ListOrdersPaginated",
+		trace.WithAttributes(attribute.Int("limit", pagination.Limit), attribute.Int("offset", pagination.Offset)))
+	defer span.End()
+
 	orders, total, err := s.repo.ListOrdersPaginated(ctx, pagination.Limit, pagination.Offset)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "Failed to list paginated orders", slog.String("error", err.Error()))
@@ -249,6 +896,126 @@ func (s *ordersService) ListOrdersPaginated(ctx context.Context, pagination *mod
 	return response, nil
 }
 
+// ListOrderEvents verifies orderID exists, then delegates to the repository
+// for the actual paginated log read.
+func (s *ordersService) ListOrderEvents(ctx context.Context, orderID int64, pagination *models.PaginationRequest) (*models.PaginatedResponse[*models.OrderEvent], error) {
+	if _, err := s.repo.GetOrderByID(ctx, orderID); err != nil {
+		switch err.(type) {
+		case *repository.OrderNotFoundError:
+			return nil, &OrderNotFoundError{ID: orderID}
+		default:
+			s.logger.ErrorContext(ctx, "Failed to verify order for event log",
+				slog.Int64("order_id", orderID), slog.String("error", err.Error()))
+			return nil, &InternalError{Message: "Failed to load order"}
+		}
+	}
+
+	events, total, err := s.repo.ListOrderEvents(ctx, orderID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list order events",
+			slog.Int64("order_id", orderID), slog.String("error", err.Error()))
+		return nil, &InternalError{Message: "Failed to list order events"}
+	}
+
+	return &models.PaginatedResponse[*models.OrderEvent]{
+		Data:   events,
+		Total:  total,
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+	}, nil
+}
+
+func (s *ordersService) RoundingMode() models.RoundingMode {
+	if p, ok := s.pricePolicy.(interface{ Mode() models.RoundingMode }); ok {
+		return p.Mode()
+	}
+	return models.RoundHalfUp
+}
+
+// defaultCurrency is the currency books are assumed to be priced in, and
+// what CreateOrder falls back to when a request doesn't specify one.
+func (s *ordersService) defaultCurrency() string {
+	if s.config != nil && s.config.DefaultCurrency != "" {
+		return s.config.DefaultCurrency
+	}
+	return "USD"
+}
+
+// ListOrdersByCursor decodes the opaque cursor, fetches one page of orders
+// ordered by (created_at, id) DESC, and re-encodes cursors for the next and
+// previous pages. See OrdersRepository.ListOrdersByCursor for what backward
+// changes about the underlying query.
+func (s *ordersService) ListOrdersByCursor(ctx context.Context, cursor string, limit int, backward bool, exactCount bool) (*models.CursorPaginationResponse[*models.Order], error) {
+	decoded, err := models.DecodeCursor(cursor, s.config.CursorSigningSecret)
+	if err != nil {
+		return nil, &InvalidCursorError{Message: err.Error()}
+	}
+
+	orders, hasMore, err := s.repo.ListOrdersByCursor(ctx, decoded, limit, backward)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list orders by cursor", slog.String("error", err.Error()))
+		return nil, &InternalError{Message: "Failed to list orders"}
+	}
+
+	if orders == nil {
+		orders = make([]*models.Order, 0)
+	}
+
+	response := &models.CursorPaginationResponse[*models.Order]{
+		Data:  orders,
+		Limit: limit,
+	}
+
+	// Best-effort: an estimate that fails to load just means the UI hint is
+	// omitted, not that the page itself fails.
+	if estimate, err := s.repo.EstimateOrderCount(ctx); err == nil {
+		response.EstimatedTotal = &estimate
+	} else {
+		s.logger.WarnContext(ctx, "Failed to estimate order count", slog.String("error", err.Error()))
+	}
+
+	// An exact count is only computed on request: it costs a full scan, so
+	// charging every cursor page for one would defeat the point of keyset
+	// pagination.
+	if exactCount {
+		if total, err := s.repo.CountOrdersExact(ctx); err == nil {
+			response.Total = &total
+		} else {
+			s.logger.WarnContext(ctx, "Failed to count orders exactly", slog.String("error", err.Error()))
+		}
+	}
+
+	if len(orders) == 0 {
+		return response, nil
+	}
+
+	first, last := orders[0], orders[len(orders)-1]
+	secret := s.config.CursorSigningSecret
+
+	if backward {
+		// We arrived here from a real page ahead of us, so a next (older)
+		// page always exists; hasMore instead answers "is there an even
+		// earlier previous page".
+		response.HasMore = true
+		response.NextCursor = models.EncodeCursor(models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, secret)
+		if hasMore {
+			response.PrevCursor = models.EncodeCursor(models.Cursor{CreatedAt: first.CreatedAt, ID: first.ID}, secret)
+		}
+	} else {
+		response.HasMore = hasMore
+		if hasMore {
+			response.NextCursor = models.EncodeCursor(models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, secret)
+		}
+		// A non-empty request cursor means this isn't the first page, so a
+		// page before it exists.
+		if cursor != "" {
+			response.PrevCursor = models.EncodeCursor(models.Cursor{CreatedAt: first.CreatedAt, ID: first.ID}, secret)
+		}
+	}
+
+	return response, nil
+}
+
 // Removed formatCentsAsDecimal - now using exact decimal arithmetic with shopspring/decimal
 
 // Service error types
@@ -260,6 +1027,18 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
+// InvalidCursorError means a ?cursor= value failed to decode or its HMAC
+// signature didn't match - either a malformed token or a tampered one. Kept
+// distinct from ValidationError because it maps to 400, not 422: the request
+// itself is well-formed, the opaque token it references just isn't usable.
+type InvalidCursorError struct {
+	Message string
+}
+
+func (e *InvalidCursorError) Error() string {
+	return e.Message
+}
+
 type BookNotFoundError struct {
 	BookID int64
 }
@@ -292,12 +1071,17 @@ func (e *ServiceUnavailableError) Error() string {
 	return e.Message
 }
 
+// IdempotencyConflictError mirrors repository.IdempotencyConflictError;
+// ExistingHash and CreatedAt describe the original request so the handler
+// layer can surface debugging detail about the mismatch.
 type IdempotencyConflictError struct {
-	Key string
+	Key          string
+	ExistingHash string
+	CreatedAt    time.Time
 }
 
 func (e *IdempotencyConflictError) Error() string {
-	return fmt.Sprintf("idempotency key '%s' already used with different request body", e.Key)
+	return fmt.Sprintf("idempotency key '%s' already used with different request body (original request at %s)", e.Key, e.CreatedAt.Format(time.RFC3339))
 }
 
 type InternalError struct {
@@ -307,3 +1091,73 @@ type InternalError struct {
 func (e *InternalError) Error() string {
 	return e.Message
 }
+
+// SignatureInvalidError means a signed order's X-Signature didn't verify
+// against its canonicalized body for the claimed signer.
+type SignatureInvalidError struct {
+	Reason string
+}
+
+func (e *SignatureInvalidError) Error() string {
+	return fmt.Sprintf("signature verification failed: %s", e.Reason)
+}
+
+// InvalidTransitionError means Action cannot be applied to an order
+// currently in From, either because the state machine forbids it or because
+// a concurrent transition moved the order out from under the caller.
+type InvalidTransitionError struct {
+	From   models.OrderStatus
+	Action models.OrderAction
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot apply action %q to order in status %q", e.Action, e.From)
+}
+
+// OrderVersionConflictError mirrors repository.OrderVersionConflictError:
+// UpdateOrderItems's compare-and-swap on updated_at lost the race, so the
+// caller observed a stale copy of the order and should re-fetch before
+// retrying.
+type OrderVersionConflictError struct {
+	ID                int64
+	ExpectedUpdatedAt time.Time
+}
+
+func (e *OrderVersionConflictError) Error() string {
+	return fmt.Sprintf("order %d was modified since %s", e.ID, e.ExpectedUpdatedAt.Format(time.RFC3339))
+}
+
+// OrderNotPendingError means UpdateOrderItems was attempted against an order
+// that has already moved out of pending, so its items are no longer
+// editable.
+type OrderNotPendingError struct {
+	ID     int64
+	Status models.OrderStatus
+}
+
+func (e *OrderNotPendingError) Error() string {
+	return fmt.Sprintf("order %d is %q, not pending", e.ID, e.Status)
+}
+
+// ClientCancelledError means the request's context was already done (client
+// disconnected or its deadline budget ran out) before Stage started, so we
+// skipped it rather than doing wasted work against Books or the database.
+type ClientCancelledError struct {
+	Stage string
+}
+
+func (e *ClientCancelledError) Error() string {
+	return fmt.Sprintf("client disconnected before %s stage", e.Stage)
+}
+
+// OrderNotCancellableError means CancelOrder was attempted against an order
+// that's no longer in a cancellable status (nextStatus only allows
+// pending/confirmed -> cancelled) - e.g. it's already shipped.
+type OrderNotCancellableError struct {
+	ID     int64
+	Status models.OrderStatus
+}
+
+func (e *OrderNotCancellableError) Error() string {
+	return fmt.Sprintf("order %d is %q and can no longer be cancelled", e.ID, e.Status)
+}
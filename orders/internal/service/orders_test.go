@@ -97,7 +97,7 @@ func TestCreateOrder_ValidMultiBookOrder(t *testing.T) {
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true} // Enable idempotency for this test
-	service := NewOrdersService(mockRepo, mockBooksClient, logger, cfg)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(cfg))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -157,7 +157,7 @@ func TestCreateOrder_BookNotFound(t *testing.T) {
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: false} // Disable idempotency for this test
-	service := NewOrdersService(mockRepo, mockBooksClient, logger, cfg)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(cfg))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -187,7 +187,7 @@ func TestCreateOrder_BookInactive(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	service := NewOrdersService(mockRepo, mockBooksClient, logger)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -217,7 +217,7 @@ func TestCreateOrder_ServiceUnavailable(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	service := NewOrdersService(mockRepo, mockBooksClient, logger)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -246,7 +246,7 @@ func TestCreateOrder_DuplicateBookIDs(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	service := NewOrdersService(mockRepo, mockBooksClient, logger)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -297,7 +297,7 @@ func TestCreateOrder_IdempotencySameKeyAndBody(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	service := NewOrdersService(mockRepo, mockBooksClient, logger)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -339,7 +339,7 @@ func TestCreateOrder_IdempotencyConflict(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	service := NewOrdersService(mockRepo, mockBooksClient, logger)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
@@ -370,7 +370,7 @@ func TestCreateOrder_ValidationError(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooksClient := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	service := NewOrdersService(mockRepo, mockBooksClient, logger)
+	service := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger))
 
 	ctx := context.WithValue(context.Background(), "request_id", "test-123")
 
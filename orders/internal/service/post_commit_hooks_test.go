@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestCreateOrder_PostCommitHooks asserts that every registered
+// WithPostCommitHook fires exactly once on a successful create, and zero
+// times when creation never actually commits (idempotency short-circuit or
+// a repository error).
+func TestCreateOrder_PostCommitHooks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name           string
+		idempotency    bool
+		idempotencyKey string
+		setupMocks     func(repo *MockOrdersRepository, books *MockBooksClient)
+		wantErr        bool
+		wantHookCalls  int
+	}{
+		{
+			name: "success fires every hook once",
+			setupMocks: func(repo *MockOrdersRepository, books *MockBooksClient) {
+				books.On("GetBooks", mock.Anything, []int64{1}).
+					Return(map[int64]*models.Book{1: {ID: 1, Title: "T", Author: "A", Price: "9.99", Active: true}}, nil)
+				repo.On("CreateOrder", mock.Anything, mock.AnythingOfType("*models.Order")).
+					Run(func(args mock.Arguments) {
+						args.Get(1).(*models.Order).ID = 1
+					}).Return(nil)
+			},
+			wantHookCalls: 2,
+		},
+		{
+			name:           "idempotency short-circuit fires no hooks",
+			idempotency:    true,
+			idempotencyKey: "dup-key",
+			setupMocks: func(repo *MockOrdersRepository, books *MockBooksClient) {
+				repo.On("CheckIdempotencyKey", mock.Anything, "dup-key", mock.AnythingOfType("string")).
+					Return(&models.Order{ID: 99}, nil)
+			},
+			wantHookCalls: 0,
+		},
+		{
+			name: "create failure fires no hooks",
+			setupMocks: func(repo *MockOrdersRepository, books *MockBooksClient) {
+				books.On("GetBooks", mock.Anything, []int64{1}).
+					Return(map[int64]*models.Book{1: {ID: 1, Title: "T", Author: "A", Price: "9.99", Active: true}}, nil)
+				repo.On("CreateOrder", mock.Anything, mock.AnythingOfType("*models.Order")).
+					Return(errors.New("db is down"))
+			},
+			wantErr:       true,
+			wantHookCalls: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockOrdersRepository)
+			mockBooks := new(MockBooksClient)
+			tt.setupMocks(mockRepo, mockBooks)
+
+			hookCallsA, hookCallsB := 0, 0
+			svc := NewOrdersService(
+				WithRepository(mockRepo),
+				WithBooksClient(mockBooks),
+				WithLogger(logger),
+				WithConfig(&config.Config{IdempotencyEnabled: tt.idempotency}),
+				WithPostCommitHook(func(ctx context.Context, order *models.Order) { hookCallsA++ }),
+				WithPostCommitHook(func(ctx context.Context, order *models.Order) { hookCallsB++ }),
+			)
+
+			req := &models.CreateOrderRequest{Items: []models.CreateOrderItemRequest{{BookID: 1, Quantity: 1}}}
+			_, err := svc.CreateOrder(context.Background(), req, tt.idempotencyKey)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantHookCalls, hookCallsA)
+			assert.Equal(t, tt.wantHookCalls, hookCallsB)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package pricing computes the adjustments (discounts, tax, shipping) that
+// sit between an order's line-item subtotal and its final total_price, so
+// those adjustments can be persisted and rendered as an itemized receipt
+// instead of being folded silently into one number.
+package pricing
+
+import "github.com/shopspring/decimal"
+
+// Adjustment kinds, used both as the Adjustment.Kind value and as the
+// persisted order_adjustments.kind column.
+const (
+	KindDiscount = "discount"
+	KindTax      = "tax"
+	KindShipping = "shipping"
+)
+
+// Line is one priced order line fed into the pipeline. Subtotal is
+// quantity*unit price in the order's currency. TaxRate, when non-zero,
+// overrides a TaxModifier's DefaultRate for this line - e.g. a reduced VAT
+// rate on books versus the standard rate a TaxModifier otherwise applies.
+type Line struct {
+	Subtotal decimal.Decimal
+	TaxRate  decimal.Decimal
+}
+
+// Adjustment is one pipeline stage's contribution: Amount is signed, so a
+// discount's Amount is negative and a tax or shipping charge's is positive.
+type Adjustment struct {
+	Kind   string
+	Label  string
+	Amount decimal.Decimal
+}
+
+// PriceModifier is one stage of the pricing pipeline. Apply receives every
+// line (so a stage like TaxModifier can rate each line individually) plus
+// the lines' combined subtotal, and returns the amount it contributes along
+// with a label and kind for the itemized adjustments array.
+type PriceModifier interface {
+	Apply(lines []Line, subtotal decimal.Decimal) (delta decimal.Decimal, label string, kind string)
+}
+
+// DiscountModifier takes a flat percentage off the order subtotal.
+type DiscountModifier struct {
+	Label   string
+	Percent decimal.Decimal
+}
+
+func (m DiscountModifier) Apply(_ []Line, subtotal decimal.Decimal) (decimal.Decimal, string, string) {
+	return subtotal.Mul(m.Percent).Round(2).Neg(), m.Label, KindDiscount
+}
+
+// TaxModifier computes VAT/GST per line rather than on the grand total, so a
+// mixed-rate cart - reduced-rate books alongside a standard-rate shipping
+// charge - taxes each line at its own rate instead of one blended rate.
+// DefaultRate applies to any line whose TaxRate is zero.
+type TaxModifier struct {
+	Label       string
+	DefaultRate decimal.Decimal
+}
+
+func (m TaxModifier) Apply(lines []Line, _ decimal.Decimal) (decimal.Decimal, string, string) {
+	total := decimal.Zero
+	for _, line := range lines {
+		rate := line.TaxRate
+		if rate.IsZero() {
+			rate = m.DefaultRate
+		}
+		total = total.Add(line.Subtotal.Mul(rate).Round(2))
+	}
+	return total, m.Label, KindTax
+}
+
+// ShippingModifier adds a flat shipping charge, independent of the lines.
+type ShippingModifier struct {
+	Label string
+	Flat  decimal.Decimal
+}
+
+func (m ShippingModifier) Apply(_ []Line, _ decimal.Decimal) (decimal.Decimal, string, string) {
+	return m.Flat, m.Label, KindShipping
+}
+
+// Pipeline runs an ordered sequence of PriceModifier stages against a set of
+// priced lines. Modifiers run in slice order and each sees the same
+// pre-adjustment subtotal, so a discount and a tax stage both read the
+// lines' raw subtotal rather than one reading the other's output.
+type Pipeline struct {
+	Modifiers []PriceModifier
+}
+
+// Result is a pipeline run's output: Subtotal plus every adjustment's
+// contribution sums to Total by construction.
+type Result struct {
+	Subtotal    decimal.Decimal
+	Adjustments []Adjustment
+	Total       decimal.Decimal
+}
+
+func (p Pipeline) Run(lines []Line) Result {
+	subtotal := decimal.Zero
+	for _, line := range lines {
+		subtotal = subtotal.Add(line.Subtotal)
+	}
+
+	adjustments := make([]Adjustment, 0, len(p.Modifiers))
+	total := subtotal
+	for _, modifier := range p.Modifiers {
+		delta, label, kind := modifier.Apply(lines, subtotal)
+		adjustments = append(adjustments, Adjustment{Kind: kind, Label: label, Amount: delta})
+		total = total.Add(delta)
+	}
+
+	return Result{Subtotal: subtotal, Adjustments: adjustments, Total: total}
+}
@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPipeline_MixedRateVAT covers a cart with a reduced-rate book line and a
+// standard-rate shipping charge: taxing the grand total at one blended rate
+// would give a different (wrong) answer than taxing each line at its own
+// rate, which is what destination-based VAT/GST rules require.
+func TestPipeline_MixedRateVAT(t *testing.T) {
+	lines := []Line{
+		{Subtotal: decimal.RequireFromString("20.00"), TaxRate: decimal.RequireFromString("0.07")}, // reduced-rate book
+		{Subtotal: decimal.RequireFromString("5.00"), TaxRate: decimal.RequireFromString("0.19")},  // standard-rate shipping line
+	}
+
+	pipeline := Pipeline{Modifiers: []PriceModifier{
+		TaxModifier{Label: "VAT", DefaultRate: decimal.RequireFromString("0.19")},
+	}}
+
+	result := pipeline.Run(lines)
+
+	assert.Equal(t, "25.00", result.Subtotal.StringFixed(2))
+	if assert.Len(t, result.Adjustments, 1) {
+		// 20.00*0.07 + 5.00*0.19 = 1.40 + 0.95 = 2.35, not 25.00*0.19 = 4.75.
+		assert.Equal(t, "2.35", result.Adjustments[0].Amount.StringFixed(2))
+	}
+	assert.Equal(t, "27.35", result.Total.StringFixed(2))
+}
+
+// TestPipeline_DiscountThenTaxThenShipping exercises all three modifier
+// kinds together and proves the sum-of-parts invariant: subtotal plus every
+// adjustment's amount equals Total exactly.
+func TestPipeline_DiscountThenTaxThenShipping(t *testing.T) {
+	lines := []Line{
+		{Subtotal: decimal.RequireFromString("100.00"), TaxRate: decimal.RequireFromString("0.19")},
+	}
+
+	pipeline := Pipeline{Modifiers: []PriceModifier{
+		DiscountModifier{Label: "SAVE10", Percent: decimal.RequireFromString("0.10")},
+		TaxModifier{Label: "VAT", DefaultRate: decimal.RequireFromString("0.19")},
+		ShippingModifier{Label: "Standard shipping", Flat: decimal.RequireFromString("4.99")},
+	}}
+
+	result := pipeline.Run(lines)
+
+	assert.Equal(t, "100.00", result.Subtotal.StringFixed(2))
+	if assert.Len(t, result.Adjustments, 3) {
+		assert.Equal(t, KindDiscount, result.Adjustments[0].Kind)
+		assert.Equal(t, "-10.00", result.Adjustments[0].Amount.StringFixed(2))
+		assert.Equal(t, KindTax, result.Adjustments[1].Kind)
+		assert.Equal(t, "19.00", result.Adjustments[1].Amount.StringFixed(2))
+		assert.Equal(t, KindShipping, result.Adjustments[2].Kind)
+		assert.Equal(t, "4.99", result.Adjustments[2].Amount.StringFixed(2))
+	}
+
+	sum := result.Subtotal
+	for _, adj := range result.Adjustments {
+		sum = sum.Add(adj.Amount)
+	}
+	assert.True(t, sum.Equal(result.Total), "subtotal + adjustments must equal total exactly")
+	assert.Equal(t, "113.99", result.Total.StringFixed(2))
+}
+
+// TestPipeline_NoModifiersLeavesTotalUnchanged covers the empty-pipeline
+// case: Total must equal the plain subtotal when there's nothing to apply.
+func TestPipeline_NoModifiersLeavesTotalUnchanged(t *testing.T) {
+	lines := []Line{
+		{Subtotal: decimal.RequireFromString("19.99")},
+	}
+
+	result := Pipeline{}.Run(lines)
+
+	assert.Empty(t, result.Adjustments)
+	assert.True(t, result.Total.Equal(result.Subtotal))
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/clients"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/metrics"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// ReconcilerStore is the subset of the orders repository the reconciler
+// needs. It is satisfied by repository.OrdersRepository.
+type ReconcilerStore interface {
+	ListOrdersCreatedSince(ctx context.Context, since time.Time) ([]*models.Order, error)
+	RecordOrderAnomaly(ctx context.Context, anomaly *models.OrderAnomaly) error
+}
+
+// Reconciler periodically re-checks recently created orders' items against
+// the Books service, since order rows snapshot book_title/book_author/
+// unit_price at creation time with no follow-up integrity check otherwise.
+// Detected drift is recorded as an OrderAnomaly and counted in
+// metrics.IncReconcileAnomaly.
+type Reconciler struct {
+	store    ReconcilerStore
+	books    clients.BooksClient
+	logger   *slog.Logger
+	interval time.Duration
+	lookback time.Duration
+}
+
+// NewReconciler builds a Reconciler that, every interval, re-checks orders
+// created within the last lookback window.
+func NewReconciler(store ReconcilerStore, books clients.BooksClient, logger *slog.Logger, interval, lookback time.Duration) *Reconciler {
+	return &Reconciler{store: store, books: books, logger: logger, interval: interval, lookback: lookback}
+}
+
+// Run blocks, reconciling every interval until ctx is cancelled.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.InfoContext(ctx, "Reconciler stopping")
+			return
+		case <-ticker.C:
+			since := time.Now().Add(-r.lookback)
+			if _, err := r.ReconcileSince(ctx, since); err != nil {
+				r.logger.ErrorContext(ctx, "Reconcile pass failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// ReconcileSince walks every order created at or after since, re-queries the
+// Books service for each item's book, and records an OrderAnomaly for every
+// instance of drift found. It returns the number of anomalies recorded.
+func (r *Reconciler) ReconcileSince(ctx context.Context, since time.Time) (int, error) {
+	orders, err := r.store.ListOrdersCreatedSince(ctx, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list orders to reconcile: %w", err)
+	}
+
+	bookIDs := make([]int64, 0, len(orders))
+	for _, order := range orders {
+		for _, item := range order.Items {
+			bookIDs = append(bookIDs, item.BookID)
+		}
+	}
+
+	books, err := r.books.GetBooks(ctx, bookIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch books for reconciliation: %w", err)
+	}
+
+	anomalies := 0
+	for _, order := range orders {
+		for _, item := range order.Items {
+			anomaly := r.detectDrift(order.ID, item, books[item.BookID])
+			if anomaly == nil {
+				continue
+			}
+			if err := r.store.RecordOrderAnomaly(ctx, anomaly); err != nil {
+				r.logger.ErrorContext(ctx, "Failed to record order anomaly",
+					slog.Int64("order_id", order.ID), slog.Int64("book_id", item.BookID), slog.String("error", err.Error()))
+				continue
+			}
+			metrics.IncReconcileAnomaly(string(anomaly.Type))
+			anomalies++
+		}
+	}
+
+	return anomalies, nil
+}
+
+// detectDrift compares item's snapshot against book (nil when the Books
+// service no longer has that ID) and returns the first anomaly found, or nil
+// if nothing has drifted. Price is compared as decimal, not string, so
+// "19.90" vs "19.9" isn't a false positive.
+func (r *Reconciler) detectDrift(orderID int64, item models.OrderItem, book *models.Book) *models.OrderAnomaly {
+	now := time.Now()
+	if book == nil {
+		return &models.OrderAnomaly{
+			OrderID: orderID, BookID: item.BookID, Type: models.AnomalyBookDeleted,
+			Detail: fmt.Sprintf("book %d no longer exists in the Books service", item.BookID), CreatedAt: now,
+		}
+	}
+
+	// Compare against BaseUnitPrice, not UnitPrice: UnitPrice is FX-converted
+	// into the order's currency (see order.go's BaseUnitPrice doc), while
+	// book.Price is always in the book's native currency. Comparing UnitPrice
+	// would flag every multi-currency order as drifted by its FX rate alone.
+	snapshotPrice, err1 := decimal.NewFromString(item.BaseUnitPrice)
+	currentPrice, err2 := decimal.NewFromString(book.Price)
+	if err1 == nil && err2 == nil && !snapshotPrice.Equal(currentPrice) {
+		return &models.OrderAnomaly{
+			OrderID: orderID, BookID: item.BookID, Type: models.AnomalyPriceDrift,
+			Detail: fmt.Sprintf("base_unit_price %s, books service %s", item.BaseUnitPrice, book.Price), CreatedAt: now,
+		}
+	}
+
+	if item.BookTitle != book.Title {
+		return &models.OrderAnomaly{
+			OrderID: orderID, BookID: item.BookID, Type: models.AnomalyTitleDrift,
+			Detail: fmt.Sprintf("book_title %q, books service %q", item.BookTitle, book.Title), CreatedAt: now,
+		}
+	}
+
+	return nil
+}
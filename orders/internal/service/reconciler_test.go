@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestReconciler(store ReconcilerStore, books *MockBooksClient) *Reconciler {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	return NewReconciler(store, books, logger, time.Hour, 24*time.Hour)
+}
+
+func TestReconcileSince_PriceDriftRecordsAnomaly(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooks := new(MockBooksClient)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	orders := []*models.Order{
+		{ID: 1, Items: []models.OrderItem{{BookID: 10, UnitPrice: "19.99", BaseUnitPrice: "19.99", BookTitle: "Go in Action"}}},
+	}
+	mockRepo.On("ListOrdersCreatedSince", mock.Anything, since).Return(orders, nil)
+	mockBooks.On("GetBooks", mock.Anything, []int64{10}).Return(map[int64]*models.Book{
+		10: {ID: 10, Title: "Go in Action", Price: "24.99"},
+	}, nil)
+	mockRepo.On("RecordOrderAnomaly", mock.Anything, mock.MatchedBy(func(a *models.OrderAnomaly) bool {
+		return a.OrderID == 1 && a.BookID == 10 && a.Type == models.AnomalyPriceDrift
+	})).Return(nil)
+
+	r := newTestReconciler(mockRepo, mockBooks)
+	anomalies, err := r.ReconcileSince(context.Background(), since)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, anomalies)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReconcileSince_DeletedBookRecordsAnomaly(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooks := new(MockBooksClient)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	orders := []*models.Order{
+		{ID: 2, Items: []models.OrderItem{{BookID: 20, UnitPrice: "9.99", BaseUnitPrice: "9.99", BookTitle: "Deleted Book"}}},
+	}
+	mockRepo.On("ListOrdersCreatedSince", mock.Anything, since).Return(orders, nil)
+	mockBooks.On("GetBooks", mock.Anything, []int64{20}).Return(map[int64]*models.Book{}, nil)
+	mockRepo.On("RecordOrderAnomaly", mock.Anything, mock.MatchedBy(func(a *models.OrderAnomaly) bool {
+		return a.OrderID == 2 && a.BookID == 20 && a.Type == models.AnomalyBookDeleted
+	})).Return(nil)
+
+	r := newTestReconciler(mockRepo, mockBooks)
+	anomalies, err := r.ReconcileSince(context.Background(), since)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, anomalies)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReconcileSince_NoDriftRecordsNothing(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooks := new(MockBooksClient)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	orders := []*models.Order{
+		{ID: 3, Items: []models.OrderItem{{BookID: 30, UnitPrice: "14.50", BaseUnitPrice: "14.50", BookTitle: "Stable Book"}}},
+	}
+	mockRepo.On("ListOrdersCreatedSince", mock.Anything, since).Return(orders, nil)
+	mockBooks.On("GetBooks", mock.Anything, []int64{30}).Return(map[int64]*models.Book{
+		30: {ID: 30, Title: "Stable Book", Price: "14.5"},
+	}, nil)
+
+	r := newTestReconciler(mockRepo, mockBooks)
+	anomalies, err := r.ReconcileSince(context.Background(), since)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, anomalies)
+	mockRepo.AssertNotCalled(t, "RecordOrderAnomaly", mock.Anything, mock.Anything)
+}
+
+// TestReconcileSince_MultiCurrencyFXRateIsNotDrift covers an order whose
+// UnitPrice has been FX-converted into the order's currency: comparing
+// UnitPrice against the Books service's native-currency Price would flag
+// every such order as drifted by its FX rate alone, forever. BaseUnitPrice
+// (the pre-conversion price) is what must match instead.
+func TestReconcileSince_MultiCurrencyFXRateIsNotDrift(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooks := new(MockBooksClient)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	orders := []*models.Order{
+		// Book priced at $24.99 USD, snapshotted into a EUR order at rate 0.92.
+		{ID: 4, Items: []models.OrderItem{{
+			BookID: 40, UnitPrice: "22.99", BaseUnitPrice: "24.99", BookTitle: "Distributed Systems",
+		}}},
+	}
+	mockRepo.On("ListOrdersCreatedSince", mock.Anything, since).Return(orders, nil)
+	mockBooks.On("GetBooks", mock.Anything, []int64{40}).Return(map[int64]*models.Book{
+		40: {ID: 40, Title: "Distributed Systems", Price: "24.99"},
+	}, nil)
+
+	r := newTestReconciler(mockRepo, mockBooks)
+	anomalies, err := r.ReconcileSince(context.Background(), since)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, anomalies)
+	mockRepo.AssertNotCalled(t, "RecordOrderAnomaly", mock.Anything, mock.Anything)
+}
@@ -9,10 +9,12 @@ import (
 	"time"
 
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
 	"github.com/infinity-9427/bookstore-microservices/orders/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetOrderByID_Service(t *testing.T) {
@@ -20,7 +22,7 @@ func TestGetOrderByID_Service(t *testing.T) {
 	mockBooks := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true}
-	svc := NewOrdersService(mockRepo, mockBooks, logger, cfg)
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg))
 
 	order := &models.Order{ID: 10, TotalPrice: "19.99"}
 	mockRepo.On("GetOrderByID", mock.Anything, int64(10)).Return(order, nil)
@@ -40,7 +42,7 @@ func TestCreateOrder_IdempotencyReuse(t *testing.T) {
 	mockBooks := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true}
-	svc := NewOrdersService(mockRepo, mockBooks, logger, cfg)
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg))
 
 	existing := &models.Order{ID: 77, TotalPrice: "19.99", Items: []models.OrderItem{{BookID: 1, Quantity: 1, UnitPrice: "19.99", TotalPrice: "19.99"}}}
 	mockRepo.On("CheckIdempotencyKey", mock.Anything, "key1", mock.AnythingOfType("string")).Return(existing, nil)
@@ -53,12 +55,35 @@ func TestCreateOrder_IdempotencyReuse(t *testing.T) {
 	mockBooks.AssertNotCalled(t, "GetBooks")
 }
 
+// TestCreateOrder_IdempotencyReuseEmitsReplayedEvent covers the idempotency
+// short-circuit publishing an order.replayed event (rather than staying
+// silent, or re-emitting order.created as if a new order had been made).
+func TestCreateOrder_IdempotencyReuseEmitsReplayedEvent(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooks := new(MockBooksClient)
+	mockEvents := new(MockEventPublisher)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cfg := &config.Config{IdempotencyEnabled: true}
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg), WithEventPublisher(mockEvents))
+
+	existing := &models.Order{ID: 78, TotalPrice: "19.99", Items: []models.OrderItem{{BookID: 1, Quantity: 1, UnitPrice: "19.99", TotalPrice: "19.99"}}}
+	mockRepo.On("CheckIdempotencyKey", mock.Anything, "key5", mock.AnythingOfType("string")).Return(existing, nil)
+	mockEvents.On("Publish", mock.Anything, mock.MatchedBy(func(e events.Event) bool {
+		return e.Type == events.OrderReplayed && e.OrderID == int64(78)
+	})).Return(nil)
+
+	req := &models.CreateOrderRequest{Items: []models.CreateOrderItemRequest{{BookID: 1, Quantity: 1}}}
+	_, err := svc.CreateOrder(context.Background(), req, "key5")
+	assert.NoError(t, err)
+	mockEvents.AssertExpectations(t)
+}
+
 func TestCreateOrder_IdempotencyRepoError(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooks := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true}
-	svc := NewOrdersService(mockRepo, mockBooks, logger, cfg)
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg))
 
 	repoErr := errors.New("db down")
 	mockRepo.On("CheckIdempotencyKey", mock.Anything, "key2", mock.AnythingOfType("string")).Return(nil, repoErr)
@@ -79,7 +104,7 @@ func TestCreateOrder_IdempotentConflict(t *testing.T) {
 	mockBooks := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: true}
-	svc := NewOrdersService(mockRepo, mockBooks, logger, cfg)
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg))
 
 	mockRepo.On("CheckIdempotencyKey", mock.Anything, "key3", mock.AnythingOfType("string")).Return(nil, &repository.IdempotencyConflictError{Key: "key3"})
 	req := &models.CreateOrderRequest{Items: []models.CreateOrderItemRequest{{BookID: 1, Quantity: 1}}}
@@ -88,12 +113,39 @@ func TestCreateOrder_IdempotentConflict(t *testing.T) {
 	assert.IsType(t, &IdempotencyConflictError{}, err)
 }
 
+// TestCreateOrder_IdempotentConflict_CarriesOriginalRequestDetail ensures the
+// original request hash and creation time survive the repository -> service
+// error translation, so callers can tell what the first request looked like.
+func TestCreateOrder_IdempotentConflict_CarriesOriginalRequestDetail(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooks := new(MockBooksClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cfg := &config.Config{IdempotencyEnabled: true}
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg))
+
+	originalCreatedAt := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("CheckIdempotencyKey", mock.Anything, "key4", mock.AnythingOfType("string")).Return(nil, &repository.IdempotencyConflictError{
+		Key:          "key4",
+		ExistingHash: "original-hash",
+		CreatedAt:    originalCreatedAt,
+	})
+
+	req := &models.CreateOrderRequest{Items: []models.CreateOrderItemRequest{{BookID: 1, Quantity: 1}}}
+	_, err := svc.CreateOrder(context.Background(), req, "key4")
+	require.Error(t, err)
+
+	conflictErr, ok := err.(*IdempotencyConflictError)
+	require.True(t, ok, "expected *IdempotencyConflictError, got %T", err)
+	assert.Equal(t, "original-hash", conflictErr.ExistingHash)
+	assert.True(t, originalCreatedAt.Equal(conflictErr.CreatedAt))
+}
+
 func TestCreateOrder_InternalCreateFailure(t *testing.T) {
 	mockRepo := new(MockOrdersRepository)
 	mockBooks := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: false}
-	svc := NewOrdersService(mockRepo, mockBooks, logger, cfg)
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg))
 
 	mockBooks.On("GetBooks", mock.Anything, []int64{int64(1)}).Return(map[int64]*models.Book{1: {ID: 1, Title: "T", Author: "A", Price: "19.99", Active: true}}, nil)
 	mockRepo.On("CreateOrder", mock.Anything, mock.AnythingOfType("*models.Order")).Return(errors.New("insert failed"))
@@ -109,7 +161,7 @@ func TestCreateOrder_MultiItemTotals(t *testing.T) {
 	mockBooks := new(MockBooksClient)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cfg := &config.Config{IdempotencyEnabled: false}
-	svc := NewOrdersService(mockRepo, mockBooks, logger, cfg)
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooks), WithLogger(logger), WithConfig(cfg))
 
 	mockBooks.On("GetBooks", mock.Anything, []int64{int64(1), int64(2)}).Return(map[int64]*models.Book{
 		1: {ID: 1, Title: "B1", Author: "A1", Price: "19.99", Active: true},
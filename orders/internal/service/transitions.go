@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/events"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/metrics"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/repository"
+)
+
+// nextStatus reports the status an order moves to when action is applied
+// from current, and whether that move is legal at all. This is the single
+// source of truth for the order lifecycle:
+//
+//	pending -> confirmed -> shipped -> delivered -> refunded
+//	pending, confirmed -> cancelled
+func nextStatus(current models.OrderStatus, action models.OrderAction) (models.OrderStatus, bool) {
+	switch action {
+	case models.ActionConfirm:
+		if current == models.StatusPending {
+			return models.StatusConfirmed, true
+		}
+	case models.ActionShip:
+		if current == models.StatusConfirmed {
+			return models.StatusShipped, true
+		}
+	case models.ActionDeliver:
+		if current == models.StatusShipped {
+			return models.StatusDelivered, true
+		}
+	case models.ActionCancel:
+		if current == models.StatusPending || current == models.StatusConfirmed {
+			return models.StatusCancelled, true
+		}
+	case models.ActionRefund:
+		if current == models.StatusDelivered {
+			return models.StatusRefunded, true
+		}
+	}
+	return "", false
+}
+
+func (s *ordersService) TransitionOrder(ctx context.Context, id int64, action models.OrderAction, reason string) (*models.Order, error) {
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		switch err.(type) {
+		case *repository.OrderNotFoundError:
+			return nil, &OrderNotFoundError{ID: id}
+		default:
+			s.logger.ErrorContext(ctx, "Failed to load order for transition",
+				slog.Int64("order_id", id), slog.String("error", err.Error()))
+			return nil, &InternalError{Message: "Failed to load order"}
+		}
+	}
+
+	to, ok := nextStatus(order.Status, action)
+	if !ok {
+		return nil, &InvalidTransitionError{From: order.Status, Action: action}
+	}
+
+	updated, err := s.repo.TransitionOrder(ctx, id, order.Status, to, reason, s.clock.Now())
+	if err != nil {
+		switch e := err.(type) {
+		case *repository.TransitionConflictError:
+			// Another request won the race; surface it the same way as an
+			// illegal transition rather than retrying automatically - the
+			// caller re-fetches and decides whether to try again.
+			return nil, &InvalidTransitionError{From: e.Expected, Action: action}
+		case *repository.OrderNotFoundError:
+			return nil, &OrderNotFoundError{ID: id}
+		default:
+			s.logger.ErrorContext(ctx, "Failed to transition order",
+				slog.Int64("order_id", id), slog.String("error", err.Error()))
+			return nil, &InternalError{Message: "Failed to transition order"}
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Order transitioned",
+		slog.Int64("order_id", id),
+		slog.String("from", string(order.Status)),
+		slog.String("to", string(to)),
+		slog.String("action", string(action)))
+
+	eventType := events.OrderStatusChanged
+	if action == models.ActionCancel {
+		eventType = events.OrderCancelled
+	}
+
+	// Best-effort, same as CreateOrder: a dropped event doesn't undo an
+	// already-committed transition.
+	if err := s.events.Publish(ctx, events.Event{
+		Type:      eventType,
+		OrderID:   id,
+		UserID:    updated.UserID,
+		Payload:   updated,
+		Timestamp: s.clock.Now(),
+	}); err != nil {
+		s.logger.WarnContext(ctx, "Failed to publish order transition event",
+			slog.Int64("order_id", id), slog.String("error", err.Error()))
+	}
+
+	return updated, nil
+}
+
+// CancelOrder goes through the same state machine as TransitionOrder with
+// ActionCancel, but reports an illegal attempt as OrderNotCancellableError
+// rather than the generic InvalidTransitionError, since "you tried to cancel
+// an order that can't be cancelled" is a more specific, callable condition
+// than "you tried some transition that isn't legal". Re-cancelling an order
+// that's already cancelled is idempotent - it returns the existing order
+// rather than erroring, since the caller's intent ("make sure this order is
+// cancelled") is already satisfied.
+func (s *ordersService) CancelOrder(ctx context.Context, id int64, reason string) (*models.Order, error) {
+	updated, err := s.TransitionOrder(ctx, id, models.ActionCancel, reason)
+	if err != nil {
+		if invalid, ok := err.(*InvalidTransitionError); ok {
+			if invalid.From == models.StatusCancelled {
+				return s.GetOrderByID(ctx, id)
+			}
+			return nil, &OrderNotCancellableError{ID: id, Status: invalid.From}
+		}
+		return nil, err
+	}
+
+	metrics.IncOrderCancellation(reason)
+	return updated, nil
+}
+
+// CancelOrdersForUser bulk-cancels userID's pending/confirmed orders in a
+// single transaction (see PostgresOrdersRepository.CancelOrdersForUser) and
+// emits an order.cancelled event per order actually cancelled.
+func (s *ordersService) CancelOrdersForUser(ctx context.Context, userID int64, reason string) ([]*models.Order, error) {
+	cancelled, err := s.repo.CancelOrdersForUser(ctx, userID, reason, s.clock.Now())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to bulk-cancel orders for user",
+			slog.Int64("user_id", userID), slog.String("error", err.Error()))
+		return nil, &InternalError{Message: "Failed to cancel orders"}
+	}
+
+	s.logger.InfoContext(ctx, "Bulk-cancelled orders for user",
+		slog.Int64("user_id", userID), slog.Int("count", len(cancelled)))
+
+	for _, order := range cancelled {
+		metrics.IncOrderCancellation(reason)
+
+		// Best-effort, same as TransitionOrder: a dropped event doesn't undo
+		// an already-committed cancellation.
+		if err := s.events.Publish(ctx, events.Event{
+			Type:      events.OrderCancelled,
+			OrderID:   order.ID,
+			UserID:    order.UserID,
+			Payload:   order,
+			Timestamp: s.clock.Now(),
+		}); err != nil {
+			s.logger.WarnContext(ctx, "Failed to publish order.cancelled event",
+				slog.Int64("order_id", order.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	return cancelled, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransitionOrder_ValidTransitions(t *testing.T) {
+	cases := []struct {
+		name   string
+		from   models.OrderStatus
+		action models.OrderAction
+		to     models.OrderStatus
+	}{
+		{"confirm", models.StatusPending, models.ActionConfirm, models.StatusConfirmed},
+		{"ship", models.StatusConfirmed, models.ActionShip, models.StatusShipped},
+		{"deliver", models.StatusShipped, models.ActionDeliver, models.StatusDelivered},
+		{"refund", models.StatusDelivered, models.ActionRefund, models.StatusRefunded},
+		{"cancel from pending", models.StatusPending, models.ActionCancel, models.StatusCancelled},
+		{"cancel from confirmed", models.StatusConfirmed, models.ActionCancel, models.StatusCancelled},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockOrdersRepository)
+			mockEvents := new(MockEventPublisher)
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			svc := NewOrdersServiceWithEvents(mockRepo, new(MockBooksClient), logger, &config.Config{}, mockEvents)
+
+			order := &models.Order{ID: 1, Status: tc.from}
+			updated := &models.Order{ID: 1, Status: tc.to}
+			mockRepo.On("GetOrderByID", mock.Anything, int64(1)).Return(order, nil)
+			mockRepo.On("TransitionOrder", mock.Anything, int64(1), tc.from, tc.to, "because", mock.Anything).Return(updated, nil)
+			mockEvents.On("Publish", mock.Anything, mock.AnythingOfType("events.Event")).Return(nil)
+
+			got, err := svc.TransitionOrder(context.Background(), 1, tc.action, "because")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.to, got.Status)
+		})
+	}
+}
+
+func TestTransitionOrder_IllegalTransition(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersServiceWithEvents(mockRepo, new(MockBooksClient), logger, &config.Config{}, new(MockEventPublisher))
+
+	order := &models.Order{ID: 2, Status: models.StatusDelivered}
+	mockRepo.On("GetOrderByID", mock.Anything, int64(2)).Return(order, nil)
+
+	_, err := svc.TransitionOrder(context.Background(), 2, models.ActionConfirm, "oops")
+	assert.Error(t, err)
+	assert.IsType(t, &InvalidTransitionError{}, err)
+}
+
+func TestTransitionOrder_NotFound(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersServiceWithEvents(mockRepo, new(MockBooksClient), logger, &config.Config{}, new(MockEventPublisher))
+
+	mockRepo.On("GetOrderByID", mock.Anything, int64(404)).Return(nil, &repository.OrderNotFoundError{ID: 404})
+
+	_, err := svc.TransitionOrder(context.Background(), 404, models.ActionCancel, "")
+	assert.Error(t, err)
+	assert.IsType(t, &OrderNotFoundError{}, err)
+}
+
+func TestCancelOrder_AlreadyCancelledIsIdempotent(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersServiceWithEvents(mockRepo, new(MockBooksClient), logger, &config.Config{}, new(MockEventPublisher))
+
+	order := &models.Order{ID: 5, Status: models.StatusCancelled}
+	mockRepo.On("GetOrderByID", mock.Anything, int64(5)).Return(order, nil)
+
+	got, err := svc.CancelOrder(context.Background(), 5, "customer_requested")
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, got.Status)
+}
+
+func TestCancelOrder_NotCancellableFromDelivered(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersServiceWithEvents(mockRepo, new(MockBooksClient), logger, &config.Config{}, new(MockEventPublisher))
+
+	order := &models.Order{ID: 6, Status: models.StatusDelivered}
+	mockRepo.On("GetOrderByID", mock.Anything, int64(6)).Return(order, nil)
+
+	_, err := svc.CancelOrder(context.Background(), 6, "customer_requested")
+	assert.Error(t, err)
+	assert.IsType(t, &OrderNotCancellableError{}, err)
+}
+
+func TestTransitionOrder_ConcurrentConflict(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersServiceWithEvents(mockRepo, new(MockBooksClient), logger, &config.Config{}, new(MockEventPublisher))
+
+	order := &models.Order{ID: 3, Status: models.StatusPending}
+	mockRepo.On("GetOrderByID", mock.Anything, int64(3)).Return(order, nil)
+	mockRepo.On("TransitionOrder", mock.Anything, int64(3), models.StatusPending, models.StatusConfirmed, "", mock.Anything).
+		Return(nil, &repository.TransitionConflictError{ID: 3, Expected: models.StatusPending})
+
+	_, err := svc.TransitionOrder(context.Background(), 3, models.ActionConfirm, "")
+	assert.Error(t, err)
+	assert.IsType(t, &InvalidTransitionError{}, err)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/config"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/models"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/repository"
+	"github.com/infinity-9427/bookstore-microservices/orders/internal/service/pricing"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateOrderItems_RecomputesTotalWithExactDecimals(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooksClient := new(MockBooksClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(&config.Config{}))
+
+	expectedUpdatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &models.UpdateOrderItemsRequest{
+		Items:             []models.CreateOrderItemRequest{{BookID: 1, Quantity: 3}},
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	}
+
+	books := map[int64]*models.Book{
+		1: {ID: 1, Title: "Test Book", Author: "Test Author", Price: "19.99", Active: true},
+	}
+	mockBooksClient.On("GetBooks", mock.Anything, []int64{1}).Return(books, nil)
+	mockRepo.On("GetOrderByID", mock.Anything, int64(1)).
+		Return(&models.Order{ID: 1, Status: models.StatusPending, Currency: "USD", FXRate: "1"}, nil)
+
+	updated := &models.Order{ID: 1, Status: models.StatusPending, TotalPrice: "59.97"}
+	mockRepo.On("UpdateOrderItems", mock.Anything, int64(1), mock.AnythingOfType("[]models.OrderItem"), []models.OrderAdjustment(nil), "59.97", "59.97", expectedUpdatedAt, mock.Anything).
+		Return(updated, nil)
+
+	result, err := svc.UpdateOrderItems(context.Background(), 1, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "59.97", result.TotalPrice, "3 x 19.99 should be exactly 59.97")
+
+	mockRepo.AssertExpectations(t)
+	mockBooksClient.AssertExpectations(t)
+}
+
+func TestUpdateOrderItems_VersionConflict(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooksClient := new(MockBooksClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(&config.Config{}))
+
+	expectedUpdatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &models.UpdateOrderItemsRequest{
+		Items:             []models.CreateOrderItemRequest{{BookID: 1, Quantity: 1}},
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	}
+
+	books := map[int64]*models.Book{
+		1: {ID: 1, Title: "Test Book", Author: "Test Author", Price: "10.00", Active: true},
+	}
+	mockBooksClient.On("GetBooks", mock.Anything, []int64{1}).Return(books, nil)
+	mockRepo.On("GetOrderByID", mock.Anything, int64(1)).
+		Return(&models.Order{ID: 1, Status: models.StatusPending, Currency: "USD", FXRate: "1"}, nil)
+	mockRepo.On("UpdateOrderItems", mock.Anything, int64(1), mock.AnythingOfType("[]models.OrderItem"), []models.OrderAdjustment(nil), "10.00", "10.00", expectedUpdatedAt, mock.Anything).
+		Return(nil, &repository.OrderVersionConflictError{ID: 1, ExpectedUpdatedAt: expectedUpdatedAt})
+
+	_, err := svc.UpdateOrderItems(context.Background(), 1, req)
+	assert.Error(t, err)
+	assert.IsType(t, &OrderVersionConflictError{}, err)
+}
+
+func TestUpdateOrderItems_NotPending(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooksClient := new(MockBooksClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(WithRepository(mockRepo), WithBooksClient(mockBooksClient), WithLogger(logger), WithConfig(&config.Config{}))
+
+	expectedUpdatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &models.UpdateOrderItemsRequest{
+		Items:             []models.CreateOrderItemRequest{{BookID: 1, Quantity: 1}},
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	}
+
+	books := map[int64]*models.Book{
+		1: {ID: 1, Title: "Test Book", Author: "Test Author", Price: "10.00", Active: true},
+	}
+	mockBooksClient.On("GetBooks", mock.Anything, []int64{1}).Return(books, nil)
+	mockRepo.On("GetOrderByID", mock.Anything, int64(1)).
+		Return(&models.Order{ID: 1, Status: models.StatusPending, Currency: "USD", FXRate: "1"}, nil)
+	mockRepo.On("UpdateOrderItems", mock.Anything, int64(1), mock.AnythingOfType("[]models.OrderItem"), []models.OrderAdjustment(nil), "10.00", "10.00", expectedUpdatedAt, mock.Anything).
+		Return(nil, &repository.OrderNotPendingError{ID: 1, Status: models.StatusShipped})
+
+	_, err := svc.UpdateOrderItems(context.Background(), 1, req)
+	assert.Error(t, err)
+	assert.IsType(t, &OrderNotPendingError{}, err)
+}
+
+// TestUpdateOrderItems_RerunsPricingPipeline covers a regression where
+// updating an order's items left its tax/shipping adjustments stale: since
+// they're derived purely from the lines, they must be recomputed from the
+// new items rather than carried over from creation time.
+func TestUpdateOrderItems_RerunsPricingPipeline(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooksClient := new(MockBooksClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(
+		WithRepository(mockRepo),
+		WithBooksClient(mockBooksClient),
+		WithLogger(logger),
+		WithConfig(&config.Config{}),
+		WithPricingPipeline(&pricing.Pipeline{Modifiers: []pricing.PriceModifier{
+			pricing.ShippingModifier{Label: "Shipping", Flat: decimal.RequireFromString("4.99")},
+		}}),
+	)
+
+	expectedUpdatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &models.UpdateOrderItemsRequest{
+		Items:             []models.CreateOrderItemRequest{{BookID: 1, Quantity: 2}},
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	}
+
+	books := map[int64]*models.Book{
+		1: {ID: 1, Title: "Test Book", Author: "Test Author", Price: "10.00", Active: true},
+	}
+	mockBooksClient.On("GetBooks", mock.Anything, []int64{1}).Return(books, nil)
+	mockRepo.On("GetOrderByID", mock.Anything, int64(1)).
+		Return(&models.Order{ID: 1, Status: models.StatusPending, Currency: "USD", FXRate: "1"}, nil)
+
+	// 2 x 10.00 = 20.00 lines, + 4.99 flat shipping = 24.99.
+	wantAdjustments := []models.OrderAdjustment{{Kind: pricing.KindShipping, Label: "Shipping", Amount: "4.99", SortOrder: 0}}
+	updated := &models.Order{ID: 1, Status: models.StatusPending, TotalPrice: "24.99", Adjustments: wantAdjustments}
+	mockRepo.On("UpdateOrderItems", mock.Anything, int64(1), mock.AnythingOfType("[]models.OrderItem"), wantAdjustments, "24.99", "20.00", expectedUpdatedAt, mock.Anything).
+		Return(updated, nil)
+
+	result, err := svc.UpdateOrderItems(context.Background(), 1, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "24.99", result.TotalPrice)
+
+	mockRepo.AssertExpectations(t)
+	mockBooksClient.AssertExpectations(t)
+}
+
+// TestUpdateOrderItems_PreservesExistingDiscount covers a regression where
+// re-running the pricing pipeline on an item update dropped a discount the
+// order was created with, since discount codes aren't re-entered on a PATCH.
+// The original discount percentage is recovered from the existing
+// adjustment and the pre-update line totals, then re-applied to the new
+// lines.
+func TestUpdateOrderItems_PreservesExistingDiscount(t *testing.T) {
+	mockRepo := new(MockOrdersRepository)
+	mockBooksClient := new(MockBooksClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewOrdersService(
+		WithRepository(mockRepo),
+		WithBooksClient(mockBooksClient),
+		WithLogger(logger),
+		WithConfig(&config.Config{}),
+		WithPricingPipeline(&pricing.Pipeline{}),
+	)
+
+	expectedUpdatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &models.UpdateOrderItemsRequest{
+		Items:             []models.CreateOrderItemRequest{{BookID: 1, Quantity: 4}},
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	}
+
+	books := map[int64]*models.Book{
+		1: {ID: 1, Title: "Test Book", Author: "Test Author", Price: "10.00", Active: true},
+	}
+	mockBooksClient.On("GetBooks", mock.Anything, []int64{1}).Return(books, nil)
+
+	// Original order: 2 x 10.00 = 20.00 subtotal, with a 10% ("SAVE10")
+	// discount of -2.00 already applied.
+	mockRepo.On("GetOrderByID", mock.Anything, int64(1)).Return(&models.Order{
+		ID: 1, Status: models.StatusPending, Currency: "USD", FXRate: "1",
+		Items:       []models.OrderItem{{BookID: 1, Quantity: 2, TotalPrice: "20.00"}},
+		Adjustments: []models.OrderAdjustment{{Kind: pricing.KindDiscount, Label: "Discount: SAVE10", Amount: "-2.00"}},
+	}, nil)
+
+	// Updated to 4 x 10.00 = 40.00, the same 10% discount now takes -4.00.
+	wantAdjustments := []models.OrderAdjustment{{Kind: pricing.KindDiscount, Label: "Discount: SAVE10", Amount: "-4.00", SortOrder: 0}}
+	updated := &models.Order{ID: 1, Status: models.StatusPending, TotalPrice: "36.00", Adjustments: wantAdjustments}
+	mockRepo.On("UpdateOrderItems", mock.Anything, int64(1), mock.AnythingOfType("[]models.OrderItem"), wantAdjustments, "36.00", "40.00", expectedUpdatedAt, mock.Anything).
+		Return(updated, nil)
+
+	result, err := svc.UpdateOrderItems(context.Background(), 1, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "36.00", result.TotalPrice)
+
+	mockRepo.AssertExpectations(t)
+	mockBooksClient.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+package telemetry
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans started by this package in exported trace
+// data; it has no bearing on the registered TracerProvider.
+const tracerName = "github.com/infinity-9427/bookstore-microservices/orders"
+
+// Middleware extracts a W3C traceparent header (if present) and starts a
+// span for the request, so a trace started by an upstream caller continues
+// here instead of starting fresh. It belongs next to metrics.Middleware()
+// in the router setup; unlike that one, it's a no-op (aside from the
+// no-op span) when no TracerProvider was configured via Setup.
+func Middleware() gin.HandlerFunc {
+	propagator := propagation.TraceContext{}
+	tracer := otel.Tracer(tracerName)
+
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		ctx, span := tracer.Start(ctx, route, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		// Read back request_id rather than the incoming header: the
+		// request-ID middleware downstream generates one when the client
+		// didn't send it, and that's still worth having on the span.
+		if requestID, ok := c.Request.Context().Value("request_id").(string); ok {
+			span.SetAttributes(attribute.String("request_id", requestID))
+		}
+		span.SetAttributes(attribute.Int("http.status_code", c.Writer.Status()))
+	}
+}
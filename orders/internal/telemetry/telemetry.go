@@ -0,0 +1,54 @@
+// Package telemetry configures OpenTelemetry tracing for the orders
+// service: an OTLP/gRPC exporter, a TracerProvider registered as the
+// global default, and a Shutdown that flushes pending spans on exit.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Config holds what Setup needs; it's a narrow copy of config.Config's
+// OTel* fields rather than a dependency on the config package, so telemetry
+// stays usable from tests and other services without pulling it in.
+type Config struct {
+	Endpoint    string
+	SampleRatio float64
+	ServiceName string
+}
+
+// Setup dials an OTLP/gRPC exporter at cfg.Endpoint, registers a
+// TracerProvider as the global default (with a W3C tracecontext
+// propagator, also registered globally), and returns a Shutdown func that
+// flushes and closes the exporter. Callers should defer the returned func.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(cfg.Endpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build telemetry resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp.Shutdown, nil
+}
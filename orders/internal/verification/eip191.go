@@ -0,0 +1,20 @@
+package verification
+
+import (
+	"context"
+	"errors"
+)
+
+// EIP191Verifier is a stub for Ethereum personal_sign (EIP-191) and
+// typed-data (EIP-712) signatures, selected via
+// SIGNATURE_VERIFICATION=eip191. Recovering a signer address from a
+// secp256k1 signature needs a real crypto library (e.g. go-ethereum/crypto),
+// which isn't a dependency of this service yet, so Verify always fails
+// rather than silently accepting unverified signatures.
+type EIP191Verifier struct{}
+
+func NewEIP191Verifier() *EIP191Verifier { return &EIP191Verifier{} }
+
+func (*EIP191Verifier) Verify(ctx context.Context, signerID string, payload []byte, signature string) error {
+	return errors.New("EIP-191/EIP-712 verification is not yet implemented")
+}
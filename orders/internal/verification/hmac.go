@@ -0,0 +1,46 @@
+package verification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// HMACVerifier is the default PayloadVerifier: signature must equal the
+// hex-encoded HMAC-SHA256 of payload under the secret registered for
+// signerID, matching how the external order-book service signs its requests.
+type HMACVerifier struct {
+	secretFor func(signerID string) (string, bool)
+}
+
+// NewHMACVerifier builds an HMACVerifier around a secret lookup func, so
+// callers can back it with a static single-tenant secret, a per-customer
+// table, or a secrets manager without changing the verifier itself.
+func NewHMACVerifier(secretFor func(signerID string) (string, bool)) *HMACVerifier {
+	return &HMACVerifier{secretFor: secretFor}
+}
+
+// NewStaticHMACVerifier is a convenience constructor for the common
+// single-shared-secret case (config.OrderSigningSecret), where every
+// signerID verifies against the same secret.
+func NewStaticHMACVerifier(secret string) *HMACVerifier {
+	return NewHMACVerifier(func(string) (string, bool) { return secret, secret != "" })
+}
+
+func (v *HMACVerifier) Verify(ctx context.Context, signerID string, payload []byte, signature string) error {
+	secret, ok := v.secretFor(signerID)
+	if !ok {
+		return errors.New("unknown signer")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+// Package verification checks that a signed order payload actually
+// originated from the customer/marketplace key it claims to.
+package verification
+
+import "context"
+
+// PayloadVerifier verifies that signature is a valid signature over payload
+// for signerID. Implementations are swapped via config (HMAC shared-secret
+// by default; a wallet-style EIP-191/712 verifier for on-chain marketplaces).
+type PayloadVerifier interface {
+	Verify(ctx context.Context, signerID string, payload []byte, signature string) error
+}